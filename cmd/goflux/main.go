@@ -1,58 +1,171 @@
 package main
 
 import (
-	"database/sql"
-	"log"
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
+	// Timezone settings work on systems without a zoneinfo database
+	_ "time/tzdata"
 
-	_ "github.com/lib/pq"
+	"github.com/twomotive/GoFlux/internal/backend"
 	"github.com/twomotive/GoFlux/internal/commands"
 	"github.com/twomotive/GoFlux/internal/config"
-	"github.com/twomotive/GoFlux/internal/database"
+	"github.com/twomotive/GoFlux/internal/logging"
+	"github.com/twomotive/GoFlux/internal/migrate"
+	"github.com/twomotive/GoFlux/internal/rssfeeds"
 	"github.com/twomotive/GoFlux/internal/state" // State paketini import edin
 )
 
 func main() {
-	cfg, err := config.Read()
+	// Global flags come before the command name, e.g. "goflux --profile work agg 1m"
+	flags := flag.NewFlagSet("goflux", flag.ExitOnError)
+	configPath := flags.String("config", "", "path to the config file")
+	profile := flags.String("profile", "", "named profile from the config file")
+	logLevel := flags.String("log-level", "info", "minimum log level: debug, info, warn or error")
+	logFormat := flags.String("log-format", logging.FormatText, "log format: text or json")
+	flags.Parse(os.Args[1:])
+
+	// Logs go to stderr so they never mix with command output
+	logger, err := logging.New(os.Stderr, *logLevel, *logFormat)
 	if err != nil {
-		log.Fatalf("error reading config: %v", err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
 	}
+	slog.SetDefault(logger)
 
-	db, err := sql.Open("postgres", cfg.DBUrl)
-	if err != nil {
-		log.Fatalf("error connecting to db: %v", err)
+	if flags.NArg() < 1 {
+		fatal("Usage: " + commands.GlobalUsage + ", run 'help' for a list of commands")
 	}
-	defer db.Close()
 
-	dbQueries := database.New(db)
+	cmds := commands.NewRegistry()
+	spec, ok := cmds.Lookup(flags.Arg(0))
+	if !ok {
+		fatal(cmds.UnknownCommand(flags.Arg(0)))
+	}
+	cmd := commands.Command{Name: spec.Name, Args: flags.Args()[1:]}
 
-	programState := state.New(dbQueries, cfg)
+	// Ctrl-C and SIGTERM cancel this context so long running commands can stop cleanly
+	signals := []os.Signal{syscall.SIGTERM, os.Interrupt}
+	if spec.Interactive {
+		// The shell stops just the running command on Ctrl-C
+		signals = signals[:1]
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), signals...)
+	defer stop()
+	// Restore the default handlers after the first signal, so a second one kills the process
+	context.AfterFunc(ctx, stop)
 
-	cmds := commands.Commands{
-		RegisteredCommands: make(map[string]func(*state.State, commands.Command) error),
+	cfg, err := config.Load(config.Options{
+		Path:       *configPath,
+		Profile:    *profile,
+		NewProfile: spec.Name == "config",
+	})
+	if err != nil {
+		fatalf("error reading config: %v", err)
 	}
-	cmds.Register("login", commands.HandlerLogin)
-	cmds.Register("register", commands.HandlerRegister)
-	cmds.Register("reset", commands.HandlerReset)
-	cmds.Register("users", commands.HandlerGetUsers)
-	cmds.Register("agg", commands.HandlerAgg)
-	cmds.Register("addfeed", commands.MiddlewareLoggedIn(commands.HandlerAddFeed))
-	cmds.Register("feeds", commands.HandlerGetFeeds)
-	cmds.Register("follow", commands.MiddlewareLoggedIn(commands.HandlerFollow))
-	cmds.Register("following", commands.MiddlewareLoggedIn(commands.HandlerFollowing))
-	cmds.Register("unfollow", commands.MiddlewareLoggedIn(commands.HandlerUnfollow))
-	cmds.Register("browse", commands.MiddlewareLoggedIn(commands.HandlerBrowse))
 
-	if len(os.Args) < 2 {
-		log.Fatal("Usage: cli <command> [args...]")
+	// Commands like config and help must work before a database is configured
+	if spec.NoDB || commands.WantsHelp(cmd.Args) {
+		if err := cmds.Run(ctx, state.New(nil, cfg, nil, nil), cmd); err != nil {
+			fatal(err)
+		}
 		return
 	}
 
-	cmdName := os.Args[1]
-	cmdArgs := os.Args[2:]
+	// Every command but migrate needs the schema this binary was built for
+	programState, closeDB, err := openState(ctx, cfg, spec.Name != "migrate")
+	if err != nil {
+		// Completion still offers what it can without a database
+		if !spec.OptionalDB {
+			fatal(err)
+		}
+		programState, closeDB = state.New(nil, cfg, nil, nil), func() {}
+	}
+	defer closeDB()
+
+	err = cmds.Run(ctx, programState, cmd)
+	if err != nil {
+		fatal(err)
+	}
+}
+
+// openState connects to the database and builds the state commands run
+// with. The returned func closes the connection
+func openState(ctx context.Context, cfg *config.Config, checkSchema bool) (*state.State, func(), error) {
+	if cfg.DBUrl == "" {
+		return nil, nil, fmt.Errorf("db_url is not set in %s, run 'config init' or set GOFLUX_DB_URL", cfg.Path())
+	}
+
+	store, err := backend.Open(cfg.DBUrl)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error connecting to db: %v", err)
+	}
+	closeDB := func() { store.DB.Close() }
+
+	migrator, err := migrate.New(store.DB, store.Migrations, store.Dialect)
+	if err != nil {
+		closeDB()
+		return nil, nil, fmt.Errorf("error loading migrations: %v", err)
+	}
+
+	if checkSchema {
+		if err := checkSchemaVersion(ctx, migrator); err != nil {
+			closeDB()
+			return nil, nil, err
+		}
+	}
+
+	hostInterval, err := cfg.Crawl.HostInterval()
+	if err != nil {
+		closeDB()
+		return nil, nil, fmt.Errorf("error reading config: %v", err)
+	}
 
-	err = cmds.Run(programState, commands.Command{Name: cmdName, Args: cmdArgs})
+	fetchTimeout, err := cfg.Crawl.Timeout()
 	if err != nil {
-		log.Fatal(err)
+		closeDB()
+		return nil, nil, fmt.Errorf("error reading config: %v", err)
 	}
+
+	fetcher := rssfeeds.NewFetcher(rssfeeds.PolitenessConfig{
+		MinHostInterval:    hostInterval,
+		MaxHostConcurrency: cfg.Crawl.MaxHostConcurrency,
+		IgnoreRobots:       cfg.Crawl.IgnoreRobots,
+		FetchTimeout:       fetchTimeout,
+	})
+
+	return state.New(store.Queries, cfg, fetcher, migrator), closeDB, nil
+}
+
+// fatal logs an error like log.Fatal, but through the configured logger
+func fatal(v ...any) {
+	slog.Error(fmt.Sprint(v...))
+	os.Exit(1)
+}
+
+func fatalf(format string, v ...any) {
+	fatal(fmt.Sprintf(format, v...))
+}
+
+// checkSchemaVersion refuses to run against a database whose schema
+// does not match the migrations embedded in this binary
+func checkSchemaVersion(ctx context.Context, migrator *migrate.Migrator) error {
+	current, err := migrator.Current(ctx)
+	if err != nil {
+		return fmt.Errorf("error checking database schema: %v", err)
+	}
+
+	latest := migrator.Latest()
+	if current < latest {
+		return fmt.Errorf("database schema is at version %d but this binary needs version %d, run 'migrate up' first", current, latest)
+	}
+	if current > latest {
+		return fmt.Errorf("database schema version %d is newer than this binary supports (%d), please upgrade GoFlux", current, latest)
+	}
+
+	return nil
 }
@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MinPasswordLength is the shortest password accepted by HashPassword
+const MinPasswordLength = 8
+
+// tokenSize is the number of random bytes in a session or API token
+const tokenSize = 32
+
+// apiTokenPrefix makes API tokens recognizable, e.g. by secret scanners
+const apiTokenPrefix = "goflux_"
+
+// Scopes limit what an API token may do. Write implies read
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+)
+
+// AllScopes is granted to password sessions
+const AllScopes = ScopeRead + "," + ScopeWrite
+
+// Roles a user account can have
+const (
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+)
+
+// ErrWrongPassword is returned by CheckPassword when the password does not match
+var ErrWrongPassword = errors.New("wrong password")
+
+// HashPassword returns the bcrypt hash of password
+func HashPassword(password string) (string, error) {
+	if len(password) < MinPasswordLength {
+		return "", fmt.Errorf("password must be at least %d characters", MinPasswordLength)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("cannot hash password: %v", err)
+	}
+	return string(hash), nil
+}
+
+// CheckPassword compares password with a hash made by HashPassword
+func CheckPassword(hash, password string) error {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return ErrWrongPassword
+	}
+	if err != nil {
+		return fmt.Errorf("cannot check password: %v", err)
+	}
+	return nil
+}
+
+// NewToken generates a random opaque token for sessions
+func NewToken() (string, error) {
+	buf := make([]byte, tokenSize)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("cannot generate token: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// NewAPIToken generates a random API token
+func NewAPIToken() (string, error) {
+	token, err := NewToken()
+	if err != nil {
+		return "", err
+	}
+	return apiTokenPrefix + token, nil
+}
+
+// ParseScopes validates a comma separated scope list and returns it normalized
+func ParseScopes(list string) (string, error) {
+	var scopes []string
+	for _, scope := range strings.Split(list, ",") {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if scope != ScopeRead && scope != ScopeWrite {
+			return "", fmt.Errorf("unknown scope '%s', use %s or %s", scope, ScopeRead, ScopeWrite)
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	slices.Sort(scopes)
+	return strings.Join(scopes, ","), nil
+}
+
+// HasScope reports whether a scope list grants scope
+func HasScope(scopes, scope string) bool {
+	granted := strings.Split(scopes, ",")
+	if slices.Contains(granted, ScopeWrite) {
+		return true
+	}
+	return slices.Contains(granted, scope)
+}
+
+// HashToken returns the form of a token stored in the database, so a
+// leaked database does not hand out working tokens
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
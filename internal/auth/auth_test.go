@@ -0,0 +1,58 @@
+package auth
+
+import "testing"
+
+func TestParseScopes(t *testing.T) {
+	tests := []struct {
+		list    string
+		want    string
+		wantErr bool
+	}{
+		{list: "read", want: "read"},
+		{list: "write", want: "write"},
+		{list: "write,read", want: "read,write"},
+		{list: " Read , READ ", want: "read"},
+		{list: "admin", wantErr: true},
+		{list: "read,", wantErr: true},
+		{list: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseScopes(tt.list)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseScopes(%q) = %q, want an error", tt.list, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseScopes(%q) returned error: %v", tt.list, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseScopes(%q) = %q, want %q", tt.list, got, tt.want)
+		}
+	}
+}
+
+func TestHasScope(t *testing.T) {
+	tests := []struct {
+		scopes string
+		scope  string
+		want   bool
+	}{
+		{scopes: "read", scope: ScopeRead, want: true},
+		{scopes: "read", scope: ScopeWrite, want: false},
+		{scopes: "write", scope: ScopeRead, want: true},
+		{scopes: "write", scope: ScopeWrite, want: true},
+		{scopes: AllScopes, scope: ScopeWrite, want: true},
+		{scopes: "", scope: ScopeRead, want: false},
+		{scopes: "readonly", scope: ScopeRead, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := HasScope(tt.scopes, tt.scope); got != tt.want {
+			t.Errorf("HasScope(%q, %q) = %v, want %v", tt.scopes, tt.scope, got, tt.want)
+		}
+	}
+}
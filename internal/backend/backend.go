@@ -0,0 +1,80 @@
+package backend
+
+import (
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	_ "github.com/lib/pq"
+	"github.com/twomotive/GoFlux/internal/database"
+	"github.com/twomotive/GoFlux/internal/database/sqlitedb"
+	"github.com/twomotive/GoFlux/internal/migrate"
+	"github.com/twomotive/GoFlux/sql/schema"
+	sqliteschema "github.com/twomotive/GoFlux/sql/sqlite/schema"
+	_ "modernc.org/sqlite"
+)
+
+// sqlitePrefix marks a db_url that points at a SQLite database file,
+// e.g. "sqlite:///home/me/goflux.db" or "sqlite:goflux.db"
+const sqlitePrefix = "sqlite:"
+
+// sqliteOptions enable foreign keys (needed for ON DELETE CASCADE), let
+// concurrent writers wait instead of failing, and store times in a format
+// SQLite's date functions understand
+const sqliteOptions = "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_time_format=sqlite"
+
+// Backend is an open database together with the matching query set
+// and migrations
+type Backend struct {
+	DB         *sql.DB
+	Queries    database.Querier
+	Migrations fs.FS
+	Dialect    migrate.Dialect
+}
+
+// Open connects to the database named by dbURL, choosing SQLite for
+// "sqlite:" URLs and Postgres for everything else
+func Open(dbURL string) (*Backend, error) {
+	if strings.HasPrefix(dbURL, sqlitePrefix) {
+		return openSQLite(dbURL)
+	}
+
+	db, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Backend{
+		DB:         db,
+		Queries:    database.New(db),
+		Migrations: schema.FS,
+		Dialect:    migrate.Postgres,
+	}, nil
+}
+
+func openSQLite(dbURL string) (*Backend, error) {
+	path := strings.TrimPrefix(strings.TrimPrefix(dbURL, sqlitePrefix), "//")
+	if path == "" {
+		return nil, fmt.Errorf("missing database file in %q", dbURL)
+	}
+
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+
+	db, err := sql.Open("sqlite", "file:"+path+separator+sqliteOptions)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows a single writer, so serialize access through one connection
+	db.SetMaxOpenConns(1)
+
+	return &Backend{
+		DB:         db,
+		Queries:    sqliteQuerier{q: sqlitedb.New(db)},
+		Migrations: sqliteschema.FS,
+		Dialect:    migrate.SQLite,
+	}, nil
+}
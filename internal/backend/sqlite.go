@@ -0,0 +1,543 @@
+package backend
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/twomotive/GoFlux/internal/database"
+	"github.com/twomotive/GoFlux/internal/database/sqlitedb"
+)
+
+// sqliteQuerier exposes the SQLite queries through database.Querier.
+// Both query sets return structurally identical rows, so most methods
+// only convert between the generated types
+type sqliteQuerier struct {
+	q *sqlitedb.Queries
+}
+
+var _ database.Querier = sqliteQuerier{}
+
+func (a sqliteQuerier) AddPostCategory(ctx context.Context, arg database.AddPostCategoryParams) error {
+	return a.q.AddPostCategory(ctx, sqlitedb.AddPostCategoryParams(arg))
+}
+
+func (a sqliteQuerier) AddPostFeed(ctx context.Context, arg database.AddPostFeedParams) error {
+	return a.q.AddPostFeed(ctx, sqlitedb.AddPostFeedParams(arg))
+}
+
+func (a sqliteQuerier) ClaimNextDigest(ctx context.Context, arg database.ClaimNextDigestParams) (database.DigestSubscription, error) {
+	row, err := a.q.ClaimNextDigest(ctx, sqlitedb.ClaimNextDigestParams(arg))
+	return database.DigestSubscription(row), err
+}
+
+func (a sqliteQuerier) ClaimNextFeed(ctx context.Context, arg database.ClaimNextFeedParams) (database.Feed, error) {
+	row, err := a.q.ClaimNextFeed(ctx, sqlitedb.ClaimNextFeedParams(arg))
+	return database.Feed(row), err
+}
+
+func (a sqliteQuerier) CountFeedHTTPSettings(ctx context.Context) (int64, error) {
+	return a.q.CountFeedHTTPSettings(ctx)
+}
+
+func (a sqliteQuerier) CountUsers(ctx context.Context) (int64, error) {
+	return a.q.CountUsers(ctx)
+}
+
+func (a sqliteQuerier) CountUsersWithPassword(ctx context.Context) (int64, error) {
+	return a.q.CountUsersWithPassword(ctx)
+}
+
+func (a sqliteQuerier) CreateAPIToken(ctx context.Context, arg database.CreateAPITokenParams) (database.ApiToken, error) {
+	row, err := a.q.CreateAPIToken(ctx, sqlitedb.CreateAPITokenParams(arg))
+	return database.ApiToken(row), err
+}
+
+func (a sqliteQuerier) CreateFeed(ctx context.Context, arg database.CreateFeedParams) (database.Feed, error) {
+	row, err := a.q.CreateFeed(ctx, sqlitedb.CreateFeedParams(arg))
+	return database.Feed(row), err
+}
+
+func (a sqliteQuerier) CreateFeedFollow(ctx context.Context, arg database.CreateFeedFollowParams) (database.CreateFeedFollowRow, error) {
+	// SQLite cannot INSERT inside a CTE, so the names are looked up separately
+	follow, err := a.q.CreateFeedFollow(ctx, sqlitedb.CreateFeedFollowParams(arg))
+	if err != nil {
+		return database.CreateFeedFollowRow{}, err
+	}
+	row, err := a.q.GetFeedFollowWithNames(ctx, follow.ID)
+	return database.CreateFeedFollowRow(row), err
+}
+
+func (a sqliteQuerier) CreatePost(ctx context.Context, arg database.CreatePostParams) (database.Post, error) {
+	row, err := a.q.CreatePost(ctx, sqlitedb.CreatePostParams(arg))
+	return database.Post(row), err
+}
+
+func (a sqliteQuerier) CreatePostRevision(ctx context.Context, arg database.CreatePostRevisionParams) error {
+	return a.q.CreatePostRevision(ctx, sqlitedb.CreatePostRevisionParams(arg))
+}
+
+func (a sqliteQuerier) CreateSession(ctx context.Context, arg database.CreateSessionParams) error {
+	return a.q.CreateSession(ctx, sqlitedb.CreateSessionParams(arg))
+}
+
+func (a sqliteQuerier) CreateUser(ctx context.Context, arg database.CreateUserParams) (database.User, error) {
+	row, err := a.q.CreateUser(ctx, sqlitedb.CreateUserParams(arg))
+	return database.User(row), err
+}
+
+func (a sqliteQuerier) CreateWebhook(ctx context.Context, arg database.CreateWebhookParams) (database.Webhook, error) {
+	row, err := a.q.CreateWebhook(ctx, sqlitedb.CreateWebhookParams(arg))
+	return database.Webhook(row), err
+}
+
+func (a sqliteQuerier) CreateWebhookDelivery(ctx context.Context, arg database.CreateWebhookDeliveryParams) error {
+	return a.q.CreateWebhookDelivery(ctx, sqlitedb.CreateWebhookDeliveryParams(arg))
+}
+
+func (a sqliteQuerier) DeleteAPIToken(ctx context.Context, arg database.DeleteAPITokenParams) (int64, error) {
+	return a.q.DeleteAPIToken(ctx, sqlitedb.DeleteAPITokenParams(arg))
+}
+
+func (a sqliteQuerier) DeleteDigestSubscription(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return a.q.DeleteDigestSubscription(ctx, userID)
+}
+
+func (a sqliteQuerier) DeleteFeed(ctx context.Context, id uuid.UUID) error {
+	return a.q.DeleteFeed(ctx, id)
+}
+
+func (a sqliteQuerier) DeleteFeedFollow(ctx context.Context, arg database.DeleteFeedFollowParams) error {
+	return a.q.DeleteFeedFollow(ctx, sqlitedb.DeleteFeedFollowParams(arg))
+}
+
+func (a sqliteQuerier) DeleteFeedHTTPSettings(ctx context.Context, feedID uuid.UUID) error {
+	return a.q.DeleteFeedHTTPSettings(ctx, feedID)
+}
+
+func (a sqliteQuerier) DeleteFeeds(ctx context.Context) error {
+	return a.q.DeleteFeeds(ctx)
+}
+
+func (a sqliteQuerier) DeletePosts(ctx context.Context) error {
+	return a.q.DeletePosts(ctx)
+}
+
+func (a sqliteQuerier) DeleteSession(ctx context.Context, tokenHash string) error {
+	return a.q.DeleteSession(ctx, tokenHash)
+}
+
+func (a sqliteQuerier) DeleteUser(ctx context.Context, name string) (int64, error) {
+	return a.q.DeleteUser(ctx, name)
+}
+
+func (a sqliteQuerier) DeleteUserSessions(ctx context.Context, userID uuid.UUID) error {
+	return a.q.DeleteUserSessions(ctx, userID)
+}
+
+func (a sqliteQuerier) DeleteUserSettings(ctx context.Context, userID uuid.UUID) error {
+	return a.q.DeleteUserSettings(ctx, userID)
+}
+
+func (a sqliteQuerier) DeleteUsers(ctx context.Context) error {
+	return a.q.DeleteUsers(ctx)
+}
+
+func (a sqliteQuerier) DeleteWebSubSubscription(ctx context.Context, feedID uuid.UUID) error {
+	return a.q.DeleteWebSubSubscription(ctx, feedID)
+}
+
+func (a sqliteQuerier) DeleteWebhook(ctx context.Context, arg database.DeleteWebhookParams) (int64, error) {
+	return a.q.DeleteWebhook(ctx, sqlitedb.DeleteWebhookParams(arg))
+}
+
+func (a sqliteQuerier) FindStoryByUrl(ctx context.Context, arg database.FindStoryByUrlParams) (uuid.NullUUID, error) {
+	return a.q.FindStoryByUrl(ctx, sqlitedb.FindStoryByUrlParams(arg))
+}
+
+func (a sqliteQuerier) GetAPITokenByHash(ctx context.Context, arg database.GetAPITokenByHashParams) (database.ApiToken, error) {
+	row, err := a.q.GetAPITokenByHash(ctx, sqlitedb.GetAPITokenByHashParams(arg))
+	return database.ApiToken(row), err
+}
+
+func (a sqliteQuerier) GetAPITokensByUser(ctx context.Context, userID uuid.UUID) ([]database.ApiToken, error) {
+	rows, err := a.q.GetAPITokensByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.ApiToken, len(rows))
+	for i, row := range rows {
+		items[i] = database.ApiToken(row)
+	}
+	return items, nil
+}
+
+func (a sqliteQuerier) GetCategories(ctx context.Context) ([]database.Category, error) {
+	rows, err := a.q.GetCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.Category, len(rows))
+	for i, row := range rows {
+		items[i] = database.Category(row)
+	}
+	return items, nil
+}
+
+func (a sqliteQuerier) GetDigestSubscriptionByUser(ctx context.Context, userID uuid.UUID) (database.DigestSubscription, error) {
+	row, err := a.q.GetDigestSubscriptionByUser(ctx, userID)
+	return database.DigestSubscription(row), err
+}
+
+func (a sqliteQuerier) GetFeedByID(ctx context.Context, id uuid.UUID) (database.Feed, error) {
+	row, err := a.q.GetFeedByID(ctx, id)
+	return database.Feed(row), err
+}
+
+func (a sqliteQuerier) GetFeedByUrl(ctx context.Context, arg database.GetFeedByUrlParams) (database.Feed, error) {
+	row, err := a.q.GetFeedByUrl(ctx, sqlitedb.GetFeedByUrlParams(arg))
+	return database.Feed(row), err
+}
+
+func (a sqliteQuerier) GetFeedFollowsByUser(ctx context.Context, userID uuid.UUID) ([]database.GetFeedFollowsByUserRow, error) {
+	rows, err := a.q.GetFeedFollowsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.GetFeedFollowsByUserRow, len(rows))
+	for i, row := range rows {
+		items[i] = database.GetFeedFollowsByUserRow(row)
+	}
+	return items, nil
+}
+
+func (a sqliteQuerier) GetFeedHTTPSettings(ctx context.Context, feedID uuid.UUID) (database.FeedHttpSetting, error) {
+	row, err := a.q.GetFeedHTTPSettings(ctx, feedID)
+	return database.FeedHttpSetting(row), err
+}
+
+func (a sqliteQuerier) GetFeedUrls(ctx context.Context) ([]database.GetFeedUrlsRow, error) {
+	rows, err := a.q.GetFeedUrls(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.GetFeedUrlsRow, len(rows))
+	for i, row := range rows {
+		items[i] = database.GetFeedUrlsRow(row)
+	}
+	return items, nil
+}
+
+func (a sqliteQuerier) GetFeeds(ctx context.Context) ([]database.Feed, error) {
+	rows, err := a.q.GetFeeds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.Feed, len(rows))
+	for i, row := range rows {
+		items[i] = database.Feed(row)
+	}
+	return items, nil
+}
+
+func (a sqliteQuerier) GetFeedsWithUserNames(ctx context.Context) ([]database.GetFeedsWithUserNamesRow, error) {
+	rows, err := a.q.GetFeedsWithUserNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.GetFeedsWithUserNamesRow, len(rows))
+	for i, row := range rows {
+		items[i] = database.GetFeedsWithUserNamesRow(row)
+	}
+	return items, nil
+}
+
+func (a sqliteQuerier) GetPostByID(ctx context.Context, id uuid.UUID) (database.Post, error) {
+	row, err := a.q.GetPostByID(ctx, id)
+	return database.Post(row), err
+}
+
+func (a sqliteQuerier) GetPostByUrl(ctx context.Context, arg database.GetPostByUrlParams) (database.Post, error) {
+	row, err := a.q.GetPostByUrl(ctx, sqlitedb.GetPostByUrlParams(arg))
+	return database.Post(row), err
+}
+
+func (a sqliteQuerier) GetPostCategories(ctx context.Context, postID uuid.UUID) ([]string, error) {
+	return a.q.GetPostCategories(ctx, postID)
+}
+
+func (a sqliteQuerier) GetPostRevisions(ctx context.Context, postID uuid.UUID) ([]database.PostRevision, error) {
+	rows, err := a.q.GetPostRevisions(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.PostRevision, len(rows))
+	for i, row := range rows {
+		items[i] = database.PostRevision(row)
+	}
+	return items, nil
+}
+
+func (a sqliteQuerier) GetPostUrls(ctx context.Context) ([]database.GetPostUrlsRow, error) {
+	rows, err := a.q.GetPostUrls(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.GetPostUrlsRow, len(rows))
+	for i, row := range rows {
+		items[i] = database.GetPostUrlsRow(row)
+	}
+	return items, nil
+}
+
+func (a sqliteQuerier) GetPostsByUser(ctx context.Context, arg database.GetPostsByUserParams) ([]database.GetPostsByUserRow, error) {
+	rows, err := a.q.GetPostsByUser(ctx, sqlitedb.GetPostsByUserParams{
+		UserID:      arg.UserID,
+		Category:    arg.Category,
+		Author:      arg.Author,
+		OldestFirst: arg.OldestFirst,
+		Limit:       int64(arg.Limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.GetPostsByUserRow, len(rows))
+	for i, row := range rows {
+		items[i] = database.GetPostsByUserRow(row)
+	}
+	return items, nil
+}
+
+func (a sqliteQuerier) GetPostsForDigest(ctx context.Context, arg database.GetPostsForDigestParams) ([]database.GetPostsForDigestRow, error) {
+	rows, err := a.q.GetPostsForDigest(ctx, sqlitedb.GetPostsForDigestParams(arg))
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.GetPostsForDigestRow, len(rows))
+	for i, row := range rows {
+		items[i] = database.GetPostsForDigestRow(row)
+	}
+	return items, nil
+}
+
+func (a sqliteQuerier) GetRecentPostTitles(ctx context.Context, arg database.GetRecentPostTitlesParams) ([]database.GetRecentPostTitlesRow, error) {
+	rows, err := a.q.GetRecentPostTitles(ctx, sqlitedb.GetRecentPostTitlesParams(arg))
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.GetRecentPostTitlesRow, len(rows))
+	for i, row := range rows {
+		items[i] = database.GetRecentPostTitlesRow(row)
+	}
+	return items, nil
+}
+
+func (a sqliteQuerier) GetSessionUser(ctx context.Context, arg database.GetSessionUserParams) (database.User, error) {
+	row, err := a.q.GetSessionUser(ctx, sqlitedb.GetSessionUserParams(arg))
+	return database.User(row), err
+}
+
+func (a sqliteQuerier) GetStoryFeeds(ctx context.Context, storyID uuid.NullUUID) ([]database.GetStoryFeedsRow, error) {
+	rows, err := a.q.GetStoryFeeds(ctx, storyID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.GetStoryFeedsRow, len(rows))
+	for i, row := range rows {
+		items[i] = database.GetStoryFeedsRow(row)
+	}
+	return items, nil
+}
+
+func (a sqliteQuerier) GetUser(ctx context.Context, name string) (database.User, error) {
+	row, err := a.q.GetUser(ctx, name)
+	return database.User(row), err
+}
+
+func (a sqliteQuerier) GetUserByID(ctx context.Context, id uuid.UUID) (database.User, error) {
+	row, err := a.q.GetUserByID(ctx, id)
+	return database.User(row), err
+}
+
+func (a sqliteQuerier) GetUserSettings(ctx context.Context, userID uuid.UUID) (database.UserSetting, error) {
+	row, err := a.q.GetUserSettings(ctx, userID)
+	return database.UserSetting{
+		UserID:      row.UserID,
+		UpdatedAt:   row.UpdatedAt,
+		Timezone:    row.Timezone,
+		DateFormat:  row.DateFormat,
+		BrowseLimit: sql.NullInt32{Int32: int32(row.BrowseLimit.Int64), Valid: row.BrowseLimit.Valid},
+		SortOrder:   row.SortOrder,
+		Language:    row.Language,
+	}, err
+}
+
+func (a sqliteQuerier) GetUsers(ctx context.Context) ([]database.User, error) {
+	rows, err := a.q.GetUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.User, len(rows))
+	for i, row := range rows {
+		items[i] = database.User(row)
+	}
+	return items, nil
+}
+
+func (a sqliteQuerier) GetWebSubSubscriptionByFeed(ctx context.Context, feedID uuid.UUID) (database.WebsubSubscription, error) {
+	row, err := a.q.GetWebSubSubscriptionByFeed(ctx, feedID)
+	return database.WebsubSubscription(row), err
+}
+
+func (a sqliteQuerier) GetWebSubSubscriptions(ctx context.Context) ([]database.GetWebSubSubscriptionsRow, error) {
+	rows, err := a.q.GetWebSubSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.GetWebSubSubscriptionsRow, len(rows))
+	for i, row := range rows {
+		items[i] = database.GetWebSubSubscriptionsRow(row)
+	}
+	return items, nil
+}
+
+func (a sqliteQuerier) GetWebhookDeliveriesByUser(ctx context.Context, arg database.GetWebhookDeliveriesByUserParams) ([]database.GetWebhookDeliveriesByUserRow, error) {
+	rows, err := a.q.GetWebhookDeliveriesByUser(ctx, sqlitedb.GetWebhookDeliveriesByUserParams{
+		UserID: arg.UserID,
+		Limit:  int64(arg.Limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.GetWebhookDeliveriesByUserRow, len(rows))
+	for i, row := range rows {
+		items[i] = database.GetWebhookDeliveriesByUserRow(row)
+	}
+	return items, nil
+}
+
+func (a sqliteQuerier) GetWebhooksByUser(ctx context.Context, userID uuid.UUID) ([]database.GetWebhooksByUserRow, error) {
+	rows, err := a.q.GetWebhooksByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.GetWebhooksByUserRow, len(rows))
+	for i, row := range rows {
+		items[i] = database.GetWebhooksByUserRow(row)
+	}
+	return items, nil
+}
+
+func (a sqliteQuerier) GetWebhooksForFeed(ctx context.Context, feedID uuid.UUID) ([]database.Webhook, error) {
+	rows, err := a.q.GetWebhooksForFeed(ctx, feedID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.Webhook, len(rows))
+	for i, row := range rows {
+		items[i] = database.Webhook(row)
+	}
+	return items, nil
+}
+
+func (a sqliteQuerier) MarkAPITokenUsed(ctx context.Context, arg database.MarkAPITokenUsedParams) error {
+	return a.q.MarkAPITokenUsed(ctx, sqlitedb.MarkAPITokenUsedParams(arg))
+}
+
+func (a sqliteQuerier) MarkDigestSent(ctx context.Context, arg database.MarkDigestSentParams) error {
+	return a.q.MarkDigestSent(ctx, sqlitedb.MarkDigestSentParams(arg))
+}
+
+func (a sqliteQuerier) MarkFeedFetched(ctx context.Context, id uuid.UUID) error {
+	return a.q.MarkFeedFetched(ctx, id)
+}
+
+func (a sqliteQuerier) MarkWebSubVerified(ctx context.Context, arg database.MarkWebSubVerifiedParams) error {
+	return a.q.MarkWebSubVerified(ctx, sqlitedb.MarkWebSubVerifiedParams(arg))
+}
+
+func (a sqliteQuerier) ReleaseDigestLease(ctx context.Context, arg database.ReleaseDigestLeaseParams) error {
+	return a.q.ReleaseDigestLease(ctx, sqlitedb.ReleaseDigestLeaseParams(arg))
+}
+
+func (a sqliteQuerier) ReleaseFeedLease(ctx context.Context, arg database.ReleaseFeedLeaseParams) error {
+	return a.q.ReleaseFeedLease(ctx, sqlitedb.ReleaseFeedLeaseParams(arg))
+}
+
+func (a sqliteQuerier) SearchPostsByUser(ctx context.Context, arg database.SearchPostsByUserParams) ([]database.SearchPostsByUserRow, error) {
+	rows, err := a.q.SearchPostsByUser(ctx, sqlitedb.SearchPostsByUserParams{
+		UserID:      arg.UserID,
+		Query:       arg.Query,
+		Category:    arg.Category,
+		Author:      arg.Author,
+		OldestFirst: arg.OldestFirst,
+		Limit:       int64(arg.Limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]database.SearchPostsByUserRow, len(rows))
+	for i, row := range rows {
+		items[i] = database.SearchPostsByUserRow(row)
+	}
+	return items, nil
+}
+
+func (a sqliteQuerier) SetFeedNormalizedUrl(ctx context.Context, arg database.SetFeedNormalizedUrlParams) error {
+	return a.q.SetFeedNormalizedUrl(ctx, sqlitedb.SetFeedNormalizedUrlParams(arg))
+}
+
+func (a sqliteQuerier) SetPostNormalizedUrl(ctx context.Context, arg database.SetPostNormalizedUrlParams) error {
+	return a.q.SetPostNormalizedUrl(ctx, sqlitedb.SetPostNormalizedUrlParams(arg))
+}
+
+func (a sqliteQuerier) SetUserRole(ctx context.Context, arg database.SetUserRoleParams) (int64, error) {
+	return a.q.SetUserRole(ctx, sqlitedb.SetUserRoleParams(arg))
+}
+
+func (a sqliteQuerier) UpdateFeedMetadata(ctx context.Context, arg database.UpdateFeedMetadataParams) error {
+	return a.q.UpdateFeedMetadata(ctx, sqlitedb.UpdateFeedMetadataParams(arg))
+}
+
+func (a sqliteQuerier) UpdatePostCanonical(ctx context.Context, arg database.UpdatePostCanonicalParams) error {
+	return a.q.UpdatePostCanonical(ctx, sqlitedb.UpdatePostCanonicalParams(arg))
+}
+
+func (a sqliteQuerier) UpdatePostContent(ctx context.Context, arg database.UpdatePostContentParams) error {
+	return a.q.UpdatePostContent(ctx, sqlitedb.UpdatePostContentParams(arg))
+}
+
+func (a sqliteQuerier) UpdateUserPassword(ctx context.Context, arg database.UpdateUserPasswordParams) error {
+	return a.q.UpdateUserPassword(ctx, sqlitedb.UpdateUserPasswordParams(arg))
+}
+
+func (a sqliteQuerier) UpsertCategory(ctx context.Context, arg database.UpsertCategoryParams) (database.Category, error) {
+	row, err := a.q.UpsertCategory(ctx, sqlitedb.UpsertCategoryParams(arg))
+	return database.Category(row), err
+}
+
+func (a sqliteQuerier) UpsertDigestSubscription(ctx context.Context, arg database.UpsertDigestSubscriptionParams) (database.DigestSubscription, error) {
+	row, err := a.q.UpsertDigestSubscription(ctx, sqlitedb.UpsertDigestSubscriptionParams(arg))
+	return database.DigestSubscription(row), err
+}
+
+func (a sqliteQuerier) UpsertFeedHTTPSettings(ctx context.Context, arg database.UpsertFeedHTTPSettingsParams) error {
+	return a.q.UpsertFeedHTTPSettings(ctx, sqlitedb.UpsertFeedHTTPSettingsParams(arg))
+}
+
+func (a sqliteQuerier) UpsertUserSettings(ctx context.Context, arg database.UpsertUserSettingsParams) error {
+	return a.q.UpsertUserSettings(ctx, sqlitedb.UpsertUserSettingsParams{
+		UserID:      arg.UserID,
+		UpdatedAt:   arg.UpdatedAt,
+		Timezone:    arg.Timezone,
+		DateFormat:  arg.DateFormat,
+		BrowseLimit: sql.NullInt64{Int64: int64(arg.BrowseLimit.Int32), Valid: arg.BrowseLimit.Valid},
+		SortOrder:   arg.SortOrder,
+		Language:    arg.Language,
+	})
+}
+
+func (a sqliteQuerier) UpsertWebSubSubscription(ctx context.Context, arg database.UpsertWebSubSubscriptionParams) (database.WebsubSubscription, error) {
+	row, err := a.q.UpsertWebSubSubscription(ctx, sqlitedb.UpsertWebSubSubscriptionParams(arg))
+	return database.WebsubSubscription(row), err
+}
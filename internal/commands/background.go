@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	// maxConcurrentDeliveries bounds how many webhook deliveries run at once
+	maxConcurrentDeliveries = 8
+	// maxConcurrentResolutions bounds how many articles are fetched at
+	// once to find their canonical URL
+	maxConcurrentResolutions = 4
+)
+
+// background runs the work that follows saving posts, so slow endpoints
+// hold up neither the feed lease nor the next fetch. Each kind has its
+// own pool, so article fetches waiting for their host do not delay
+// webhooks. Every agg or websub serve run drains its own
+type background struct {
+	webhooks  *taskPool
+	canonical *taskPool
+}
+
+func newBackground() *background {
+	return &background{
+		webhooks:  newTaskPool("webhook delivery", maxConcurrentDeliveries),
+		canonical: newTaskPool("canonical resolution", maxConcurrentResolutions),
+	}
+}
+
+// Drain waits for both pools, up to grace in total
+func (b *background) Drain(grace time.Duration) {
+	deadline := time.Now().Add(grace)
+	for _, pool := range []*taskPool{b.webhooks, b.canonical} {
+		pool.Drain(time.Until(deadline))
+	}
+}
+
+// taskPool runs tasks in goroutines, a bounded number at a time
+type taskPool struct {
+	name   string
+	ctx    context.Context
+	cancel context.CancelFunc
+	slots  chan struct{}
+	wg     sync.WaitGroup
+}
+
+func newTaskPool(name string, size int) *taskPool {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &taskPool{name: name, ctx: ctx, cancel: cancel, slots: make(chan struct{}, size)}
+}
+
+// Go queues task to run once a slot is free. Its context is only
+// cancelled by Drain, not by the caller's. Tasks still queued when
+// Drain gives up are dropped
+func (p *taskPool) Go(task func(ctx context.Context)) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		select {
+		case p.slots <- struct{}{}:
+		case <-p.ctx.Done():
+			p.dropped()
+			return
+		}
+		defer func() { <-p.slots }()
+		// A slot freed by a cancelled task must not start a queued one
+		if p.ctx.Err() != nil {
+			p.dropped()
+			return
+		}
+		task(p.ctx)
+	}()
+}
+
+func (p *taskPool) dropped() {
+	slog.Warn("dropped background task while shutting down", "task", p.name)
+}
+
+// Drain waits up to grace for queued and running tasks, then cancels
+// the ones left and waits for them to return. The pool takes no new
+// work afterwards
+func (p *taskPool) Drain(grace time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return
+	case <-time.After(grace):
+	}
+	p.cancel()
+	<-done
+}
@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTaskPoolBoundsConcurrency(t *testing.T) {
+	pool := newTaskPool("test", 2)
+	var running, peak, finished atomic.Int32
+	for range 6 {
+		pool.Go(func(ctx context.Context) {
+			n := running.Add(1)
+			for {
+				old := peak.Load()
+				if n <= old || peak.CompareAndSwap(old, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			running.Add(-1)
+			finished.Add(1)
+		})
+	}
+
+	pool.Drain(time.Minute)
+	if got := finished.Load(); got != 6 {
+		t.Errorf("finished %d tasks, want 6", got)
+	}
+	if got := peak.Load(); got > 2 {
+		t.Errorf("ran %d tasks at once, want at most 2", got)
+	}
+}
+
+func TestTaskPoolDrainCancels(t *testing.T) {
+	pool := newTaskPool("test", 1)
+	var cancelled, skipped atomic.Bool
+	started := make(chan struct{})
+	pool.Go(func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+		cancelled.Store(true)
+	})
+	<-started
+	skipped.Store(true)
+	pool.Go(func(ctx context.Context) {
+		skipped.Store(false)
+	})
+
+	pool.Drain(10 * time.Millisecond)
+	if !cancelled.Load() {
+		t.Error("running task was not cancelled")
+	}
+	if !skipped.Load() {
+		t.Error("queued task ran after the pool was cancelled")
+	}
+}
@@ -1,28 +1,229 @@
 package commands
 
 import (
-	"errors"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
 
+	"github.com/twomotive/GoFlux/internal/database"
 	"github.com/twomotive/GoFlux/internal/state"
 )
 
+// Command is one invocation. Flags are parsed out of the arguments
+// before the handler runs, so Args only holds positional arguments
 type Command struct {
-	Name string
-	Args []string
+	Name  string
+	Args  []string
+	Flags map[string]string // Flag values by long name, defaults included
+	spec  *Spec
 }
 
+// Flag returns the value of a flag declared by the command
+func (c Command) Flag(name string) string {
+	return c.Flags[name]
+}
+
+// Bool reports whether a switch declared by the command was given
+func (c Command) Bool(name string) bool {
+	return c.Flags[name] == "true"
+}
+
+// usageError describes how the command is meant to be run
+func (c Command) usageError() error {
+	if c.spec == nil {
+		return fmt.Errorf("usage: %s", c.Name)
+	}
+	return fmt.Errorf("usage: %s", c.spec.usageLine())
+}
+
+// Flag describes an option accepted by a command
+type Flag struct {
+	Name    string // Long name, given as --name
+	Short   string // Optional single letter, given as -x
+	Value   string // Placeholder for the value, empty for switches
+	Default string
+	Usage   string
+	// Complete offers values for the flag in shell completion
+	Complete Completer
+}
+
+// Spec describes a command for dispatch, help and completion
+type Spec struct {
+	Name     string
+	Aliases  []string
+	Summary  string
+	Usage    []string // Argument synopses after the name, one per form
+	Flags    []Flag
+	Examples []string
+	Admin    bool // Only admins may run it, implies login
+	NoDB     bool // Runs before a database connection is opened
+	Hidden   bool // Left out of help and completion
+	// OptionalDB commands still run when the database cannot be opened
+	OptionalDB bool
+	// Interactive commands handle Ctrl-C themselves instead of exiting
+	Interactive bool
+	// RawArgs commands receive their arguments without flag parsing
+	RawArgs bool
+	// Complete offers positional arguments in shell completion
+	Complete Completer
+	// Scope returns the API token scope a UserHandler needs for one
+	// invocation. Commands leaving it nil need write
+	Scope func(cmd Command) string
+
+	// Exactly one handler is set. UserHandler requires a logged in user
+	Handler     func(ctx context.Context, s *state.State, cmd Command) error
+	UserHandler func(ctx context.Context, s *state.State, cmd Command, user database.User) error
+}
+
+// RequiresLogin reports whether the command needs a logged in user
+func (spec *Spec) RequiresLogin() bool {
+	return spec.UserHandler != nil
+}
+
+// usageLine joins every form of the command on one line
+func (spec *Spec) usageLine() string {
+	if len(spec.Usage) == 0 {
+		return spec.Name + spec.flagSynopsis()
+	}
+	forms := make([]string, len(spec.Usage))
+	for i, form := range spec.Usage {
+		forms[i] = strings.TrimSpace(spec.Name + " " + form)
+	}
+	return strings.Join(forms, " | ")
+}
+
+// flagSynopsis lists the flags of commands whose usage does not mention them
+func (spec *Spec) flagSynopsis() string {
+	var parts []string
+	for _, f := range spec.Flags {
+		if f.Value == "" {
+			parts = append(parts, fmt.Sprintf(" [--%s]", f.Name))
+		} else {
+			parts = append(parts, fmt.Sprintf(" [--%s <%s>]", f.Name, f.Value))
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+func (spec *Spec) flag(name string) (Flag, bool) {
+	for _, f := range spec.Flags {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Flag{}, false
+}
+
+func (spec *Spec) shortFlag(letter string) (Flag, bool) {
+	for _, f := range spec.Flags {
+		if f.Short != "" && f.Short == letter {
+			return f, true
+		}
+	}
+	return Flag{}, false
+}
+
+// Commands is the registry of everything the CLI can run
 type Commands struct {
-	RegisteredCommands map[string]func(*state.State, Command) error
+	specs  []*Spec
+	byName map[string]*Spec
+}
+
+// NewCommands creates an empty registry
+func NewCommands() *Commands {
+	return &Commands{byName: make(map[string]*Spec)}
+}
+
+// Register adds a command under its name and aliases
+func (c *Commands) Register(spec Spec) {
+	if (spec.Handler == nil) == (spec.UserHandler == nil) {
+		panic(fmt.Sprintf("command %s needs exactly one handler", spec.Name))
+	}
+	if spec.Admin && spec.UserHandler == nil {
+		panic(fmt.Sprintf("admin command %s needs a UserHandler", spec.Name))
+	}
+
+	registered := &spec
+	c.specs = append(c.specs, registered)
+	for _, name := range append([]string{spec.Name}, spec.Aliases...) {
+		if _, exists := c.byName[name]; exists {
+			panic(fmt.Sprintf("command name %s registered twice", name))
+		}
+		c.byName[name] = registered
+	}
+}
+
+// Lookup finds a command by name or alias
+func (c *Commands) Lookup(name string) (*Spec, bool) {
+	spec, ok := c.byName[name]
+	return spec, ok
 }
 
-func (c *Commands) Register(name string, f func(*state.State, Command) error) {
-	c.RegisteredCommands[name] = f
+// Specs returns every command sorted by name
+func (c *Commands) Specs() []*Spec {
+	specs := append([]*Spec(nil), c.specs...)
+	sort.Slice(specs, func(i, j int) bool {
+		return specs[i].Name < specs[j].Name
+	})
+	return specs
 }
 
-func (c *Commands) Run(s *state.State, cmd Command) error {
-	f, ok := c.RegisteredCommands[cmd.Name]
+// Run parses the arguments of cmd against its spec and calls the handler.
+// "--help" on any command prints its help instead
+func (c *Commands) Run(ctx context.Context, s *state.State, cmd Command) error {
+	spec, ok := c.Lookup(cmd.Name)
 	if !ok {
-		return errors.New("command not found")
+		return c.UnknownCommand(cmd.Name)
+	}
+
+	if spec.RawArgs {
+		cmd = Command{Name: spec.Name, Args: cmd.Args, spec: spec}
+	} else {
+		if WantsHelp(cmd.Args) {
+			printCommandHelp(spec)
+			return nil
+		}
+
+		args, flags, err := parseFlags(spec, cmd.Args)
+		if err != nil {
+			return err
+		}
+		cmd = Command{Name: spec.Name, Args: args, Flags: flags, spec: spec}
+	}
+
+	switch {
+	case spec.Admin:
+		return MiddlewareAdmin(spec.UserHandler)(ctx, s, cmd)
+	case spec.RequiresLogin():
+		return MiddlewareLoggedIn(spec.UserHandler)(ctx, s, cmd)
+	default:
+		return spec.Handler(ctx, s, cmd)
+	}
+}
+
+// WantsHelp reports whether args ask for help instead of running the command
+func WantsHelp(args []string) bool {
+	for _, arg := range args {
+		if arg == "--" {
+			return false
+		}
+		if arg == "--help" || arg == "-h" {
+			return true
+		}
+	}
+	return false
+}
+
+// UnknownCommand reports a command name that is not registered,
+// suggesting the closest match
+func (c *Commands) UnknownCommand(name string) error {
+	names := make([]string, 0, len(c.byName))
+	for registered := range c.byName {
+		names = append(names, registered)
+	}
+	if suggestion := closest(name, names); suggestion != "" {
+		return fmt.Errorf("unknown command '%s', did you mean '%s'? Run 'help' for a list", name, suggestion)
 	}
-	return f(s, cmd)
+	return fmt.Errorf("unknown command '%s', run 'help' for a list", name)
 }
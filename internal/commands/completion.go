@@ -0,0 +1,295 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/twomotive/GoFlux/internal/auth"
+	"github.com/twomotive/GoFlux/internal/state"
+)
+
+// Completer returns candidates for the next positional argument, given
+// the positional arguments typed so far. Each candidate may carry a
+// description after a tab
+type Completer func(ctx context.Context, s *state.State, args []string) []string
+
+// globalValueFlags take a value when given before the command name
+var globalValueFlags = map[string]bool{
+	"--config":     true,
+	"--profile":    true,
+	"--log-level":  true,
+	"--log-format": true,
+}
+
+const bashCompletion = `# bash completion for goflux, generated by 'goflux completion bash'
+_goflux() {
+    local cur words cword
+    if declare -F _get_comp_words_by_ref >/dev/null 2>&1; then
+        _get_comp_words_by_ref -n =: cur words cword
+    else
+        cur="${COMP_WORDS[COMP_CWORD]}"
+        words=("${COMP_WORDS[@]}")
+        cword=$COMP_CWORD
+    fi
+
+    local IFS=$'\n'
+    COMPREPLY=($("${words[0]}" __complete "${words[@]:1:cword-1}" "$cur" 2>/dev/null | cut -f1))
+    if declare -F __ltrim_colon_completions >/dev/null 2>&1; then
+        __ltrim_colon_completions "$cur"
+    fi
+}
+complete -o default -F _goflux goflux
+`
+
+const zshCompletion = `#compdef goflux
+# zsh completion for goflux, generated by 'goflux completion zsh'
+_goflux() {
+    local -a lines completions
+    local line
+    lines=("${(@f)$(${words[1]} __complete "${(@)words[2,CURRENT-1]}" "${words[CURRENT]}" 2>/dev/null)}")
+    for line in $lines; do
+        [[ -z $line ]] && continue
+        if [[ $line == *$'\t'* ]]; then
+            completions+=("${${line%%$'\t'*}//:/\\:}:${line#*$'\t'}")
+        else
+            completions+=("${line//:/\\:}")
+        fi
+    done
+    _describe 'goflux' completions
+}
+compdef _goflux goflux
+`
+
+const fishCompletion = `# fish completion for goflux, generated by 'goflux completion fish'
+function __goflux_complete
+    set -l tokens (commandline -opc)
+    $tokens[1] __complete $tokens[2..-1] (commandline -ct) 2>/dev/null
+end
+complete -c goflux -f -a '(__goflux_complete)'
+`
+
+// handlerCompletion prints a completion script for the given shell
+func handlerCompletion(ctx context.Context, s *state.State, cmd Command) error {
+	if len(cmd.Args) != 1 {
+		return cmd.usageError()
+	}
+
+	switch cmd.Args[0] {
+	case "bash":
+		fmt.Print(bashCompletion)
+	case "zsh":
+		fmt.Print(zshCompletion)
+	case "fish":
+		fmt.Print(fishCompletion)
+	default:
+		return cmd.usageError()
+	}
+	return nil
+}
+
+// handlerComplete prints the candidates for the last word of a partial
+// command line, one per line. The completion scripts call it on every tab
+func (c *Commands) handlerComplete(ctx context.Context, s *state.State, cmd Command) error {
+	for _, candidate := range c.complete(ctx, s, cmd.Args) {
+		fmt.Println(candidate)
+	}
+	return nil
+}
+
+// complete works out what the last of words can be. words holds the
+// command line after the program name, ending with the word being typed
+func (c *Commands) complete(ctx context.Context, s *state.State, words []string) []string {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	current := words[len(words)-1]
+	words = words[:len(words)-1]
+
+	// Skip global flags to find the command name
+	for len(words) > 0 && strings.HasPrefix(words[0], "-") {
+		if globalValueFlags[words[0]] {
+			words = words[1:]
+		}
+		words = words[1:]
+	}
+
+	if len(words) == 0 {
+		if strings.HasPrefix(current, "-") {
+			return filterPrefix(current, []string{"--config", "--profile", "--log-level", "--log-format"})
+		}
+		return filterPrefix(current, c.completeCommandNames(ctx, s, nil))
+	}
+
+	spec, ok := c.Lookup(words[0])
+	if !ok {
+		return nil
+	}
+	args := words[1:]
+
+	// The value of a flag
+	if len(args) > 0 {
+		name, isFlag := strings.CutPrefix(args[len(args)-1], "--")
+		if f, ok := spec.flag(name); isFlag && ok && f.Value != "" {
+			if f.Complete == nil {
+				return nil
+			}
+			return filterPrefix(current, f.Complete(ctx, s, nil))
+		}
+	}
+
+	if strings.HasPrefix(current, "-") {
+		var candidates []string
+		for _, f := range spec.Flags {
+			candidates = append(candidates, "--"+f.Name+"\t"+f.Usage)
+		}
+		return filterPrefix(current, candidates)
+	}
+
+	positional, _, err := parseFlags(spec, args)
+	if err != nil {
+		return nil
+	}
+	var candidates []string
+	if spec.Complete != nil {
+		candidates = spec.Complete(ctx, s, positional)
+	}
+	if candidates == nil && len(positional) == 0 {
+		candidates = spec.subcommands()
+	}
+	return filterPrefix(current, candidates)
+}
+
+// subcommands lists the literal first words of the command's usage forms
+func (spec *Spec) subcommands() []string {
+	var names []string
+	for _, form := range spec.Usage {
+		first, _, _ := strings.Cut(form, " ")
+		if first == "" || strings.ContainsAny(first[:1], "<[") {
+			continue
+		}
+		names = append(names, strings.Split(first, "|")...)
+	}
+	return names
+}
+
+func filterPrefix(prefix string, candidates []string) []string {
+	var matches []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, prefix) {
+			matches = append(matches, candidate)
+		}
+	}
+	return matches
+}
+
+// completeFeedURLs offers the URL of every feed as the first argument
+func completeFeedURLs(ctx context.Context, s *state.State, args []string) []string {
+	if len(args) != 0 || s.DB == nil {
+		return nil
+	}
+
+	feeds, err := s.DB.GetFeeds(ctx)
+	if err != nil {
+		return nil
+	}
+	urls := make([]string, 0, len(feeds))
+	for _, feed := range feeds {
+		urls = append(urls, feed.Url+"\t"+feed.Name)
+	}
+	return urls
+}
+
+// completeFollowedURLs offers the feeds the logged in user follows
+func completeFollowedURLs(ctx context.Context, s *state.State, args []string) []string {
+	if len(args) != 0 || s.DB == nil {
+		return nil
+	}
+
+	user, _, err := authenticate(ctx, s)
+	if err != nil {
+		return nil
+	}
+	follows, err := s.DB.GetFeedFollowsByUser(ctx, user.ID)
+	if err != nil {
+		return nil
+	}
+	urls := make([]string, 0, len(follows))
+	for _, follow := range follows {
+		urls = append(urls, follow.FeedUrl+"\t"+follow.FeedName)
+	}
+	return urls
+}
+
+// completeUserNames offers every user name as the first argument
+func completeUserNames(ctx context.Context, s *state.State, args []string) []string {
+	if len(args) != 0 || s.DB == nil {
+		return nil
+	}
+
+	users, err := s.DB.GetUsers(ctx)
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(users))
+	for _, user := range users {
+		names = append(names, user.Name+"\t"+user.Role)
+	}
+	return names
+}
+
+// completeUsers offers user names after 'users delete' and 'users role'
+func completeUsers(ctx context.Context, s *state.State, args []string) []string {
+	if len(args) == 1 && (args[0] == "delete" || args[0] == "role") {
+		return completeUserNames(ctx, s, nil)
+	}
+	if len(args) == 2 && args[0] == "role" {
+		return []string{auth.RoleAdmin, auth.RoleMember}
+	}
+	return nil
+}
+
+// completeFeedHTTP offers feeds, then settings, then auth types
+func completeFeedHTTP(ctx context.Context, s *state.State, args []string) []string {
+	switch {
+	case len(args) == 0:
+		return completeFeedURLs(ctx, s, nil)
+	case len(args) == 1:
+		return []string{"show", "auth", "header", "cookie", "user-agent", "proxy", "clear"}
+	case len(args) == 2 && args[1] == "auth":
+		return []string{"basic", "bearer", "none"}
+	}
+	return nil
+}
+
+// completeCategories offers every category seen in posts
+func completeCategories(ctx context.Context, s *state.State, args []string) []string {
+	if len(args) != 0 || s.DB == nil {
+		return nil
+	}
+
+	categories, err := s.DB.GetCategories(ctx)
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(categories))
+	for _, category := range categories {
+		names = append(names, category.Name)
+	}
+	return names
+}
+
+// completeCommandNames offers the commands 'help' can describe
+func (c *Commands) completeCommandNames(ctx context.Context, s *state.State, args []string) []string {
+	if len(args) != 0 {
+		return nil
+	}
+
+	var names []string
+	for _, spec := range c.Specs() {
+		if !spec.Hidden {
+			names = append(names, spec.Name+"\t"+spec.Summary)
+		}
+	}
+	return names
+}
@@ -0,0 +1,132 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// parseFlags splits args into positional arguments and flag values
+// following POSIX and GNU conventions: flags may appear anywhere,
+// "--name=value" and "--name value" are equivalent, short switches can
+// be grouped as in "-yv", and "--" ends flag parsing
+func parseFlags(spec *Spec, args []string) ([]string, map[string]string, error) {
+	values := make(map[string]string, len(spec.Flags))
+	for _, f := range spec.Flags {
+		if f.Value == "" {
+			values[f.Name] = "false"
+		} else {
+			values[f.Name] = f.Default
+		}
+	}
+
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			positional = append(positional, args[i+1:]...)
+			return positional, values, nil
+		case strings.HasPrefix(arg, "--"):
+			name, value, hasValue := strings.Cut(arg[2:], "=")
+			f, ok := spec.flag(name)
+			if !ok {
+				return nil, nil, unknownFlag(spec, "--"+name)
+			}
+			if f.Value == "" {
+				if hasValue {
+					return nil, nil, fmt.Errorf("flag --%s does not take a value", name)
+				}
+				values[f.Name] = "true"
+				continue
+			}
+			if !hasValue {
+				if i+1 >= len(args) {
+					return nil, nil, fmt.Errorf("missing value for --%s", name)
+				}
+				i++
+				value = args[i]
+			}
+			values[f.Name] = value
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			// Each letter is a switch, until one that takes a value
+			// consumes the rest of the group or the next argument
+			letters := arg[1:]
+			for j := 0; j < len(letters); j++ {
+				f, ok := spec.shortFlag(letters[j : j+1])
+				if !ok {
+					return nil, nil, unknownFlag(spec, "-"+letters[j:j+1])
+				}
+				if f.Value == "" {
+					values[f.Name] = "true"
+					continue
+				}
+				if rest := letters[j+1:]; rest != "" {
+					values[f.Name] = rest
+				} else if i+1 < len(args) {
+					i++
+					values[f.Name] = args[i]
+				} else {
+					return nil, nil, fmt.Errorf("missing value for -%s", f.Short)
+				}
+				break
+			}
+		default:
+			positional = append(positional, arg)
+		}
+	}
+
+	return positional, values, nil
+}
+
+func unknownFlag(spec *Spec, given string) error {
+	names := make([]string, 0, len(spec.Flags))
+	for _, f := range spec.Flags {
+		names = append(names, "--"+f.Name)
+	}
+	if suggestion := closest(given, names); suggestion != "" {
+		return fmt.Errorf("unknown flag %s for %s, did you mean %s?", given, spec.Name, suggestion)
+	}
+	return fmt.Errorf("unknown flag %s for %s, run 'help %s' for its flags", given, spec.Name, spec.Name)
+}
+
+// closest returns the candidate nearest to given by edit distance,
+// or "" when none is close enough to be a plausible typo
+func closest(given string, candidates []string) string {
+	maxDistance := 2
+	if len(given) <= 3 {
+		maxDistance = 1
+	}
+
+	candidates = append([]string(nil), candidates...)
+	sort.Strings(candidates)
+
+	best, bestDistance := "", maxDistance+1
+	for _, candidate := range candidates {
+		if d := editDistance(given, candidate); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
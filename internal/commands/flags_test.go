@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseFlags(t *testing.T) {
+	spec := &Spec{
+		Name: "browse",
+		Flags: []Flag{
+			{Name: "feed", Short: "f", Value: "url"},
+			{Name: "limit", Short: "n", Value: "count", Default: "2"},
+			{Name: "yes", Short: "y"},
+			{Name: "verbose", Short: "v"},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		positional []string
+		flags      map[string]string
+	}{
+		{
+			name:  "defaults",
+			flags: map[string]string{"feed": "", "limit": "2", "yes": "false", "verbose": "false"},
+		},
+		{
+			name:       "long flags anywhere",
+			args:       []string{"go", "--limit", "5", "rust", "--feed=https://example.com/rss"},
+			positional: []string{"go", "rust"},
+			flags:      map[string]string{"feed": "https://example.com/rss", "limit": "5", "yes": "false", "verbose": "false"},
+		},
+		{
+			name:  "grouped short switches",
+			args:  []string{"-yv"},
+			flags: map[string]string{"feed": "", "limit": "2", "yes": "true", "verbose": "true"},
+		},
+		{
+			name:  "short flag value in the group",
+			args:  []string{"-yn10"},
+			flags: map[string]string{"feed": "", "limit": "10", "yes": "true", "verbose": "false"},
+		},
+		{
+			name:  "short flag value as next argument",
+			args:  []string{"-n", "7", "--yes"},
+			flags: map[string]string{"feed": "", "limit": "7", "yes": "true", "verbose": "false"},
+		},
+		{
+			name:       "double dash ends flags",
+			args:       []string{"--yes", "--", "--limit", "-v"},
+			positional: []string{"--limit", "-v"},
+			flags:      map[string]string{"feed": "", "limit": "2", "yes": "true", "verbose": "false"},
+		},
+		{
+			name:       "single dash is positional",
+			args:       []string{"-"},
+			positional: []string{"-"},
+			flags:      map[string]string{"feed": "", "limit": "2", "yes": "false", "verbose": "false"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			positional, flags, err := parseFlags(spec, tt.args)
+			if err != nil {
+				t.Fatalf("parseFlags(%q) returned error: %v", tt.args, err)
+			}
+			if !reflect.DeepEqual(positional, tt.positional) {
+				t.Errorf("positional = %q, want %q", positional, tt.positional)
+			}
+			if !reflect.DeepEqual(flags, tt.flags) {
+				t.Errorf("flags = %v, want %v", flags, tt.flags)
+			}
+		})
+	}
+}
+
+func TestParseFlagsErrors(t *testing.T) {
+	spec := &Spec{
+		Name: "browse",
+		Flags: []Flag{
+			{Name: "limit", Short: "n", Value: "count"},
+			{Name: "yes", Short: "y"},
+		},
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "unknown long flag with suggestion", args: []string{"--limt", "3"}, want: "unknown flag --limt for browse, did you mean --limit?"},
+		{name: "unknown long flag", args: []string{"--color"}, want: "unknown flag --color for browse, run 'help browse' for its flags"},
+		{name: "unknown short flag", args: []string{"-yx"}, want: "unknown flag -x for browse"},
+		{name: "switch given a value", args: []string{"--yes=no"}, want: "flag --yes does not take a value"},
+		{name: "missing long value", args: []string{"--limit"}, want: "missing value for --limit"},
+		{name: "missing short value", args: []string{"-n"}, want: "missing value for -n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := parseFlags(spec, tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("parseFlags(%q) error = %v, want one containing %q", tt.args, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestClosest(t *testing.T) {
+	candidates := []string{"browse", "feeds", "follow", "following", "login", "logout"}
+
+	tests := []struct {
+		given string
+		want  string
+	}{
+		{given: "browse", want: "browse"},
+		{given: "brwose", want: "browse"},
+		{given: "folow", want: "follow"},
+		{given: "followin", want: "following"},
+		{given: "logn", want: "login"},
+		{given: "feed", want: "feeds"},
+		{given: "fed", want: ""},
+		{given: "register", want: ""},
+		{given: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := closest(tt.given, candidates); got != tt.want {
+			t.Errorf("closest(%q) = %q, want %q", tt.given, got, tt.want)
+		}
+	}
+}
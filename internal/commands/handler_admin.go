@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/twomotive/GoFlux/internal/auth"
+	"github.com/twomotive/GoFlux/internal/database"
+	"github.com/twomotive/GoFlux/internal/state"
+)
+
+// MiddlewareAdmin wraps handlers that only admins may run
+func MiddlewareAdmin(handler func(ctx context.Context, s *state.State, cmd Command, user database.User) error) func(context.Context, *state.State, Command) error {
+	return MiddlewareLoggedIn(func(ctx context.Context, s *state.State, cmd Command, user database.User) error {
+		if user.Role != auth.RoleAdmin {
+			return fmt.Errorf("'%s' requires an admin account", cmd.Name)
+		}
+		return handler(ctx, s, cmd, user)
+	})
+}
+
+// confirm asks the user to type "yes" before a destructive action,
+// unless --yes was given
+func confirm(warning string, yes bool) error {
+	if yes {
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "%s Type 'yes' to continue: ", warning)
+	answer, err := stdin.ReadString('\n')
+	if strings.TrimSpace(answer) != "yes" {
+		if err != nil {
+			return fmt.Errorf("aborted, pass --yes to run without a prompt")
+		}
+		return fmt.Errorf("aborted")
+	}
+	return nil
+}
+
+// handlerUsersAdmin deletes accounts and changes their roles
+func handlerUsersAdmin(ctx context.Context, s *state.State, cmd Command, user database.User) error {
+	switch cmd.Args[0] {
+	case "delete":
+		if len(cmd.Args) != 2 {
+			return cmd.usageError()
+		}
+		name := cmd.Args[1]
+		if name == user.Name {
+			return fmt.Errorf("you cannot delete your own account")
+		}
+
+		if err := confirm(fmt.Sprintf("This deletes '%s' with all their feeds, follows and posts.", name), cmd.Bool("yes")); err != nil {
+			return err
+		}
+		deleted, err := s.DB.DeleteUser(ctx, name)
+		if err != nil {
+			return fmt.Errorf("couldn't delete user: %v", err)
+		}
+		if deleted == 0 {
+			return fmt.Errorf("user with name '%s' doesnt exists", name)
+		}
+		fmt.Printf("User '%s' deleted\n", name)
+	case "role":
+		if len(cmd.Args) != 3 {
+			return cmd.usageError()
+		}
+		name, role := cmd.Args[1], cmd.Args[2]
+		if role != auth.RoleAdmin && role != auth.RoleMember {
+			return cmd.usageError()
+		}
+		// Keeps at least one admin around
+		if name == user.Name && role != auth.RoleAdmin {
+			return fmt.Errorf("you cannot remove your own admin role")
+		}
+
+		updated, err := s.DB.SetUserRole(ctx, database.SetUserRoleParams{
+			Name:      name,
+			Role:      role,
+			UpdatedAt: time.Now().UTC(),
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't change role: %v", err)
+		}
+		if updated == 0 {
+			return fmt.Errorf("user with name '%s' doesnt exists", name)
+		}
+		fmt.Printf("User '%s' is now %s\n", name, role)
+	default:
+		return cmd.usageError()
+	}
+
+	return nil
+}
+
+// HandlerRemoveFeed deletes a feed for everyone following it. Only admins may run it
+func HandlerRemoveFeed(ctx context.Context, s *state.State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 1 {
+		return cmd.usageError()
+	}
+
+	feed, err := feedByURL(ctx, s, cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("cannot get feed from database: %v", err)
+	}
+
+	if err := confirm(fmt.Sprintf("This deletes '%s' with its posts for every follower.", feed.Name), cmd.Bool("yes")); err != nil {
+		return err
+	}
+	if err := s.DB.DeleteFeed(ctx, feed.ID); err != nil {
+		return fmt.Errorf("couldn't delete feed: %v", err)
+	}
+
+	fmt.Printf("Feed '%s' removed\n", feed.Name)
+	return nil
+}
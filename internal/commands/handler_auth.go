@@ -0,0 +1,309 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/twomotive/GoFlux/internal/auth"
+	"github.com/twomotive/GoFlux/internal/database"
+	"github.com/twomotive/GoFlux/internal/state"
+	"golang.org/x/term"
+)
+
+// sessionTTL is how long a login stays valid
+const sessionTTL = 30 * 24 * time.Hour
+
+// stdin is shared by all prompts so buffered input is not lost between them
+var stdin = bufio.NewReader(os.Stdin)
+
+// authenticate resolves the logged in user and the scopes granted to it.
+// An API token from the config or GOFLUX_TOKEN takes precedence over the session
+func authenticate(ctx context.Context, s *state.State) (database.User, string, error) {
+	if s.Cfg.Token != "" {
+		return tokenUser(ctx, s)
+	}
+
+	user, err := sessionUser(ctx, s)
+	return user, auth.AllScopes, err
+}
+
+// requiredScope returns the scope an API token needs to run a command.
+// Commands that do not declare one need write
+func requiredScope(cmd Command) string {
+	if cmd.spec == nil || cmd.spec.Scope == nil {
+		return auth.ScopeWrite
+	}
+	return cmd.spec.Scope(cmd)
+}
+
+// readScope is the Scope of commands that only read
+func readScope(cmd Command) string {
+	return auth.ScopeRead
+}
+
+// readSubcommands is the Scope of commands mixing reading and writing
+// subcommands. It lists the read-only ones, "" standing for none given
+func readSubcommands(names ...string) func(cmd Command) string {
+	return func(cmd Command) string {
+		sub := ""
+		if len(cmd.Args) > 0 {
+			sub = cmd.Args[0]
+		}
+		if slices.Contains(names, sub) {
+			return auth.ScopeRead
+		}
+		return auth.ScopeWrite
+	}
+}
+
+// tokenUser resolves the API token stored in the config
+func tokenUser(ctx context.Context, s *state.State) (database.User, string, error) {
+	now := time.Now().UTC()
+	token, err := s.DB.GetAPITokenByHash(ctx, database.GetAPITokenByHashParams{
+		TokenHash: auth.HashToken(s.Cfg.Token),
+		ExpiresAt: sql.NullTime{Time: now, Valid: true},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.User{}, "", fmt.Errorf("API token is invalid, expired or revoked")
+	}
+	if err != nil {
+		return database.User{}, "", fmt.Errorf("token validation failed: %w", err)
+	}
+
+	user, err := s.DB.GetUserByID(ctx, token.UserID)
+	if err != nil {
+		return database.User{}, "", fmt.Errorf("token validation failed: %w", err)
+	}
+
+	err = s.DB.MarkAPITokenUsed(ctx, database.MarkAPITokenUsedParams{
+		ID:         token.ID,
+		LastUsedAt: sql.NullTime{Time: now, Valid: true},
+	})
+	if err != nil {
+		return database.User{}, "", fmt.Errorf("couldn't record token use: %v", err)
+	}
+
+	return user, token.Scopes, nil
+}
+
+// sessionUser resolves the session token stored in the config
+func sessionUser(ctx context.Context, s *state.State) (database.User, error) {
+	if s.Cfg.SessionToken == "" {
+		return database.User{}, fmt.Errorf("no user logged in, please log in first")
+	}
+
+	user, err := s.DB.GetSessionUser(ctx, database.GetSessionUserParams{
+		TokenHash: auth.HashToken(s.Cfg.SessionToken),
+		ExpiresAt: time.Now().UTC(),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.User{}, fmt.Errorf("session expired or revoked, please log in again")
+	}
+	if err != nil {
+		return database.User{}, fmt.Errorf("user validation failed: %w", err)
+	}
+
+	return user, nil
+}
+
+// startSession creates a session for user and stores its token in the config
+func startSession(ctx context.Context, s *state.State, user database.User) error {
+	token, err := auth.NewToken()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	err = s.DB.CreateSession(ctx, database.CreateSessionParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		ExpiresAt: now.Add(sessionTTL),
+		TokenHash: auth.HashToken(token),
+		UserID:    user.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't create session: %v", err)
+	}
+
+	if err := s.Cfg.SetSession(token); err != nil {
+		return fmt.Errorf("couldn't save session: %v", err)
+	}
+	return nil
+}
+
+// readPassword prompts for a password without echoing it on a terminal.
+// Piped input is read one line at a time so scripts can provide passwords
+func readPassword(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		password, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("cannot read password: %v", err)
+		}
+		return string(password), nil
+	}
+
+	line, err := stdin.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("cannot read password: %v", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// readNewPassword asks for a new password twice and returns its hash
+func readNewPassword() (string, error) {
+	password, err := readPassword("New password: ")
+	if err != nil {
+		return "", err
+	}
+	confirm, err := readPassword("Repeat password: ")
+	if err != nil {
+		return "", err
+	}
+	if password != confirm {
+		return "", fmt.Errorf("passwords do not match")
+	}
+
+	return auth.HashPassword(password)
+}
+
+// HandlerPasswd changes the password of the logged in user and
+// signs out every other session. Admins may set another user's password
+// with --user, which is how accounts without one get their first
+func HandlerPasswd(ctx context.Context, s *state.State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 0 {
+		return cmd.usageError()
+	}
+	if name := cmd.Flag("user"); name != "" && name != user.Name {
+		return resetPassword(ctx, s, user, name)
+	}
+
+	if user.PasswordHash.Valid {
+		password, err := readPassword("Current password: ")
+		if err != nil {
+			return err
+		}
+		if err := auth.CheckPassword(user.PasswordHash.String, password); err != nil {
+			return err
+		}
+	}
+
+	hash, err := readNewPassword()
+	if err != nil {
+		return err
+	}
+
+	err = s.DB.UpdateUserPassword(ctx, database.UpdateUserPasswordParams{
+		ID:           user.ID,
+		PasswordHash: sql.NullString{String: hash, Valid: true},
+		UpdatedAt:    time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't update password: %v", err)
+	}
+
+	if err := s.DB.DeleteUserSessions(ctx, user.ID); err != nil {
+		return fmt.Errorf("couldn't end old sessions: %v", err)
+	}
+	if err := startSession(ctx, s, user); err != nil {
+		return err
+	}
+
+	fmt.Println("Password changed, other sessions have been logged out.")
+	return nil
+}
+
+// resetPassword lets an admin set the password of another user and
+// logs that user out everywhere
+func resetPassword(ctx context.Context, s *state.State, admin database.User, name string) error {
+	if admin.Role != auth.RoleAdmin {
+		return fmt.Errorf("only admins can set another user's password")
+	}
+
+	target, err := s.DB.GetUser(ctx, name)
+	if err != nil {
+		return fmt.Errorf("user with name '%s' doesnt exists", name)
+	}
+
+	hash, err := readNewPassword()
+	if err != nil {
+		return err
+	}
+
+	err = s.DB.UpdateUserPassword(ctx, database.UpdateUserPasswordParams{
+		ID:           target.ID,
+		PasswordHash: sql.NullString{String: hash, Valid: true},
+		UpdatedAt:    time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't update password: %v", err)
+	}
+	if err := s.DB.DeleteUserSessions(ctx, target.ID); err != nil {
+		return fmt.Errorf("couldn't end sessions of %s: %v", name, err)
+	}
+
+	fmt.Printf("Password set for '%s', their sessions have been logged out.\n", name)
+	return nil
+}
+
+// setFirstAdminPassword handles logging in to an account without a
+// password. Such accounts predate passwords and must get one from an
+// admin, except that an admin may choose their own while no account
+// has a password yet, as nobody could run the reset otherwise
+func setFirstAdminPassword(ctx context.Context, s *state.State, user database.User) error {
+	count, err := s.DB.CountUsersWithPassword(ctx)
+	if err != nil {
+		return fmt.Errorf("couldn't count users with a password: %v", err)
+	}
+	if count > 0 || user.Role != auth.RoleAdmin {
+		return fmt.Errorf("user '%s' has no password, ask an admin to set one with 'passwd --user %s'", user.Name, user.Name)
+	}
+
+	fmt.Printf("No account has a password yet, please choose one for admin '%s'.\n", user.Name)
+	hash, err := readNewPassword()
+	if err != nil {
+		return err
+	}
+	err = s.DB.UpdateUserPassword(ctx, database.UpdateUserPasswordParams{
+		ID:           user.ID,
+		PasswordHash: sql.NullString{String: hash, Valid: true},
+		UpdatedAt:    time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't set password: %v", err)
+	}
+	return nil
+}
+
+// HandlerLogout ends the current session
+func HandlerLogout(ctx context.Context, s *state.State, cmd Command) error {
+	if len(cmd.Args) != 0 {
+		return cmd.usageError()
+	}
+	if s.Cfg.SessionToken == "" {
+		return fmt.Errorf("no user logged in")
+	}
+
+	err := s.DB.DeleteSession(ctx, auth.HashToken(s.Cfg.SessionToken))
+	if err != nil {
+		return fmt.Errorf("couldn't end session: %v", err)
+	}
+	if err := s.Cfg.SetSession(""); err != nil {
+		return fmt.Errorf("couldn't clear session: %v", err)
+	}
+
+	fmt.Println("Logged out.")
+	return nil
+}
@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/twomotive/GoFlux/internal/auth"
+)
+
+func TestRequiredScope(t *testing.T) {
+	registry := NewRegistry()
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "browse", want: auth.ScopeRead},
+		{name: "following", want: auth.ScopeRead},
+		{name: "follow", args: []string{"https://example.com/rss"}, want: auth.ScopeWrite},
+		{name: "addfeed", args: []string{"Example", "https://example.com/rss"}, want: auth.ScopeWrite},
+		{name: "settings", want: auth.ScopeRead},
+		{name: "settings", args: []string{"show"}, want: auth.ScopeRead},
+		{name: "settings", args: []string{"set", "sort", "oldest"}, want: auth.ScopeWrite},
+		{name: "webhook", want: auth.ScopeWrite},
+		{name: "webhook", args: []string{"list"}, want: auth.ScopeRead},
+		{name: "webhook", args: []string{"log", "5"}, want: auth.ScopeRead},
+		{name: "webhook", args: []string{"remove", "1"}, want: auth.ScopeWrite},
+		{name: "digest", args: []string{"preview"}, want: auth.ScopeRead},
+		{name: "digest", args: []string{"send"}, want: auth.ScopeWrite},
+	}
+
+	for _, tt := range tests {
+		spec, ok := registry.Lookup(tt.name)
+		if !ok {
+			t.Fatalf("command %s is not registered", tt.name)
+		}
+		cmd := Command{Name: tt.name, Args: tt.args, spec: spec}
+		if got := requiredScope(cmd); got != tt.want {
+			t.Errorf("requiredScope(%s %q) = %s, want %s", tt.name, tt.args, got, tt.want)
+		}
+	}
+
+	if got := requiredScope(Command{Name: "unknown"}); got != auth.ScopeWrite {
+		t.Errorf("requiredScope without a spec = %s, want %s", got, auth.ScopeWrite)
+	}
+}
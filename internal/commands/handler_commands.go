@@ -3,12 +3,16 @@ package commands
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"log/slog"
+	"os"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/twomotive/GoFlux/internal/auth"
 	"github.com/twomotive/GoFlux/internal/database"
 	"github.com/twomotive/GoFlux/internal/rssfeeds"
 	"github.com/twomotive/GoFlux/internal/state"
@@ -16,68 +20,96 @@ import (
 
 // MiddlewareLoggedIn wraps handlers requiring a logged-in user
 // It takes a handler that expects a user object and returns a standard handler
-func MiddlewareLoggedIn(handler func(s *state.State, cmd Command, user database.User) error) func(*state.State, Command) error {
-	return func(s *state.State, cmd Command) error {
-		// Check if there's a current user in config
-		if s.Cfg.CurrentUsername == "" {
-			return fmt.Errorf("no user logged in, please log in first")
-		}
-
-		// Get user from database
-		currentUser, err := s.DB.GetUser(context.Background(), s.Cfg.CurrentUsername)
+func MiddlewareLoggedIn(handler func(ctx context.Context, s *state.State, cmd Command, user database.User) error) func(context.Context, *state.State, Command) error {
+	return func(ctx context.Context, s *state.State, cmd Command) error {
+		// Resolve the user from the API token or session in config
+		currentUser, scopes, err := authenticate(ctx, s)
 		if err != nil {
-			return fmt.Errorf("user validation failed: %w", err)
+			return err
+		}
+		if scope := requiredScope(cmd); !auth.HasScope(scopes, scope) {
+			return fmt.Errorf("API token lacks the '%s' scope needed for %s", scope, cmd.Name)
 		}
 
 		// Call the original handler with the user
-		return handler(s, cmd, currentUser)
+		return handler(ctx, s, cmd, currentUser)
 	}
 }
 
-func HandlerLogin(s *state.State, cmd Command) error {
-	if len(cmd.Args) == 0 {
-		return fmt.Errorf("empty argument")
+func HandlerLogin(ctx context.Context, s *state.State, cmd Command) error {
+	if len(cmd.Args) != 1 {
+		return cmd.usageError()
 	}
 	name := cmd.Args[0]
 
-	_, err := s.DB.GetUser(context.Background(), name)
+	user, err := s.DB.GetUser(ctx, name)
 	if err != nil {
 		return fmt.Errorf("user with name '%s' doesnt exists", name)
 	}
 
-	err = s.Cfg.SetUser(name)
-	if err != nil {
-		return fmt.Errorf("couldn't set current user: %w", err)
+	if !user.PasswordHash.Valid {
+		if err := setFirstAdminPassword(ctx, s, user); err != nil {
+			return err
+		}
+	} else {
+		password, err := readPassword("Password: ")
+		if err != nil {
+			return err
+		}
+		if err := auth.CheckPassword(user.PasswordHash.String, password); err != nil {
+			return err
+		}
+	}
+
+	if err := startSession(ctx, s, user); err != nil {
+		return err
 	}
 
 	fmt.Println("User switched successfully!")
 	return nil
 }
 
-func HandlerRegister(s *state.State, cmd Command) error {
+func HandlerRegister(ctx context.Context, s *state.State, cmd Command) error {
 	if len(cmd.Args) != 1 {
-		return fmt.Errorf("usage: %v <name>", cmd.Name)
+		return cmd.usageError()
 	}
 	name := cmd.Args[0]
 
-	_, err := s.DB.GetUser(context.Background(), name)
+	_, err := s.DB.GetUser(ctx, name)
 	if err == nil {
 		// User exists (no error means the query found a user)
 		return fmt.Errorf("user with name '%s' already exists", name)
 	}
 
-	newUser, err := s.DB.CreateUser(context.Background(), database.CreateUserParams{
-		ID:        uuid.New(),
-		CreatedAt: time.Now().UTC(),
-		UpdatedAt: time.Now().UTC(),
-		Name:      name,
+	hash, err := readNewPassword()
+	if err != nil {
+		return err
+	}
+
+	// The first account on a fresh database administers it
+	role := auth.RoleMember
+	count, err := s.DB.CountUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("couldn't count users: %w", err)
+	}
+	if count == 0 {
+		role = auth.RoleAdmin
+	}
+
+	newUser, err := s.DB.CreateUser(ctx, database.CreateUserParams{
+		ID:           uuid.New(),
+		CreatedAt:    time.Now().UTC(),
+		UpdatedAt:    time.Now().UTC(),
+		Name:         name,
+		PasswordHash: sql.NullString{String: hash, Valid: true},
+		Role:         role,
 	})
 
 	if err != nil {
 		return fmt.Errorf("couldn't create user: %w", err)
 	}
 
-	err = s.Cfg.SetUser(name)
+	err = startSession(ctx, s, newUser)
 	if err != nil {
 		return fmt.Errorf("couldn't set user :%v", err)
 	}
@@ -87,75 +119,129 @@ func HandlerRegister(s *state.State, cmd Command) error {
 
 }
 
-func HandlerReset(s *state.State, cmd Command) error {
-	// The verification is unnecessary, as command name is already "reset"
-	// Just check if user explicitly confirms the reset
-	if len(cmd.Args) != 0 {
-		return fmt.Errorf("usage: reset")
+// HandlerReset wipes users, feeds or posts. Only admins may run it
+func HandlerReset(ctx context.Context, s *state.State, cmd Command, user database.User) error {
+	postsOnly, feedsOnly, yes := cmd.Bool("posts-only"), cmd.Bool("feeds-only"), cmd.Bool("yes")
+	if len(cmd.Args) != 0 || (postsOnly && feedsOnly) {
+		return cmd.usageError()
 	}
 
-	err := s.DB.DeleteUsers(context.Background())
-	if err != nil {
-		return fmt.Errorf("cannot reset users table: %w", err)
-	}
+	switch {
+	case postsOnly:
+		if err := confirm("This deletes every stored post.", yes); err != nil {
+			return err
+		}
+		if err := s.DB.DeletePosts(ctx); err != nil {
+			return fmt.Errorf("cannot reset posts table: %w", err)
+		}
+		fmt.Println("All posts have been deleted successfully!")
+	case feedsOnly:
+		if err := confirm("This deletes every feed with its follows and posts.", yes); err != nil {
+			return err
+		}
+		if err := s.DB.DeleteFeeds(ctx); err != nil {
+			return fmt.Errorf("cannot reset feeds table: %w", err)
+		}
+		fmt.Println("All feeds have been deleted successfully!")
+	default:
+		if err := confirm("This deletes every user with all their feeds, follows and posts.", yes); err != nil {
+			return err
+		}
+		if err := s.DB.DeleteUsers(ctx); err != nil {
+			return fmt.Errorf("cannot reset users table: %w", err)
+		}
 
-	// Also clear the current user in configuration
-	err = s.Cfg.SetUser("")
-	if err != nil {
-		return fmt.Errorf("cleared users table but couldn't reset current user: %w", err)
+		// Also clear the session in configuration
+		if err := s.Cfg.SetSession(""); err != nil {
+			return fmt.Errorf("cleared users table but couldn't reset current user: %w", err)
+		}
+		fmt.Println("All users have been deleted successfully!")
 	}
 
-	fmt.Println("All users have been deleted successfully!")
 	return nil
 }
 
-func HandlerGetUsers(s *state.State, cmd Command) error {
-	if len(cmd.Args) != 0 {
-		return fmt.Errorf("usage: users")
+func HandlerGetUsers(ctx context.Context, s *state.State, cmd Command) error {
+	if len(cmd.Args) > 0 && cmd.Args[0] != "list" {
+		// Everything but listing changes other accounts
+		return MiddlewareAdmin(handlerUsersAdmin)(ctx, s, cmd)
+	}
+	if len(cmd.Args) > 1 {
+		return cmd.usageError()
 	}
 
-	users, err := s.DB.GetUsers(context.Background())
+	users, err := s.DB.GetUsers(ctx)
 	if err != nil {
 		return fmt.Errorf("couldn't get users from database: %v", err)
 	}
 
-	currentUser := s.Cfg.CurrentUsername
+	// Listing users does not require a login, so a stale session just marks no one
+	currentUser, _, _ := authenticate(ctx, s)
 
 	for _, user := range users {
-		if user.Name == currentUser {
-			fmt.Printf("* %v (current)\n", user.Name)
-		} else {
-			fmt.Printf("* %v\n", user.Name)
+		line := fmt.Sprintf("* %v", user.Name)
+		if user.Role == auth.RoleAdmin {
+			line += " [admin]"
 		}
+		if user.ID == currentUser.ID {
+			line += " (current)"
+		}
+		fmt.Println(line)
 	}
 
 	return nil
 
 }
 
-func HandlerAddFeed(s *state.State, cmd Command, user database.User) error {
-	if len(cmd.Args) != 2 {
-		return fmt.Errorf("usage: %v <name> <url>", cmd.Name)
+func HandlerAddFeed(ctx context.Context, s *state.State, cmd Command, user database.User) error {
+	if len(cmd.Args) < 1 || len(cmd.Args) > 2 {
+		return cmd.usageError()
 	}
 
-	feedName := cmd.Args[0]
-	url := cmd.Args[1]
+	url := cmd.Args[len(cmd.Args)-1]
+	if existing, err := feedByURL(ctx, s, url); err == nil {
+		return fmt.Errorf("feed '%s' already exists as %s, follow it instead", existing.Name, existing.Url)
+	}
 
-	newFeed, err := s.DB.CreateFeed(context.Background(), database.CreateFeedParams{
-		ID:        uuid.New(),
-		CreatedAt: time.Now().UTC(),
-		UpdatedAt: time.Now().UTC(),
-		Name:      feedName,
-		Url:       url,
-		UserID:    user.ID,
+	var feedName string
+	var rssFeed *rssfeeds.RSSFeed
+	if len(cmd.Args) == 2 {
+		feedName = cmd.Args[0]
+	} else {
+		// Without a name, the feed is named after its channel title
+		var err error
+		rssFeed, err = s.Fetcher.Fetch(ctx, url, rssfeeds.FetchOptions{})
+		if err != nil {
+			return fmt.Errorf("cannot fetch feed for its title, give a name instead: %v", err)
+		}
+		feedName = strings.TrimSpace(rssFeed.Channel.Title)
+		if feedName == "" {
+			return fmt.Errorf("feed has no title, give a name instead")
+		}
+	}
+
+	newFeed, err := s.DB.CreateFeed(ctx, database.CreateFeedParams{
+		ID:            uuid.New(),
+		CreatedAt:     time.Now().UTC(),
+		UpdatedAt:     time.Now().UTC(),
+		Name:          feedName,
+		Url:           url,
+		UserID:        user.ID,
+		NormalizedUrl: normalizeURL(s, url),
 	})
 
 	if err != nil {
 		return fmt.Errorf("error creating feed: %v", err)
 	}
 
+	if rssFeed != nil {
+		if err := saveFeedMetadata(ctx, s, newFeed.ID, rssFeed); err != nil {
+			slog.Error("cannot save feed metadata", "feed_id", newFeed.ID, "error", err)
+		}
+	}
+
 	// Automatically follow the feed after creation
-	followResult, err := s.DB.CreateFeedFollow(context.Background(), database.CreateFeedFollowParams{
+	followResult, err := s.DB.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
 		ID:        uuid.New(),
 		CreatedAt: time.Now().UTC(),
 		UpdatedAt: time.Now().UTC(),
@@ -170,12 +256,12 @@ func HandlerAddFeed(s *state.State, cmd Command, user database.User) error {
 	return nil
 }
 
-func HandlerGetFeeds(s *state.State, cmd Command) error {
+func HandlerGetFeeds(ctx context.Context, s *state.State, cmd Command) error {
 	if len(cmd.Args) != 0 {
-		return fmt.Errorf("usage: feeds")
+		return cmd.usageError()
 	}
 
-	feeds, err := s.DB.GetFeedsWithUserNames(context.Background())
+	feeds, err := s.DB.GetFeedsWithUserNames(ctx)
 	if err != nil {
 		return fmt.Errorf("cannot get feeds from database: %v", err)
 	}
@@ -196,20 +282,20 @@ func HandlerGetFeeds(s *state.State, cmd Command) error {
 	return nil
 }
 
-func HandlerFollow(s *state.State, cmd Command, user database.User) error {
+func HandlerFollow(ctx context.Context, s *state.State, cmd Command, user database.User) error {
 	if len(cmd.Args) != 1 {
-		return fmt.Errorf("usage: %v <url>", cmd.Name)
+		return cmd.usageError()
 	}
 
 	url := cmd.Args[0]
 
 	// No need to query for the current user - it's passed in by middleware
-	feedByUrl, err := s.DB.GetFeedByUrl(context.Background(), url)
+	feedByUrl, err := feedByURL(ctx, s, url)
 	if err != nil {
 		return fmt.Errorf("cannot get feed from database: %v", err)
 	}
 
-	_, err = s.DB.CreateFeedFollow(context.Background(), database.CreateFeedFollowParams{
+	_, err = s.DB.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
 		ID:        uuid.New(),
 		CreatedAt: time.Now().UTC(),
 		UpdatedAt: time.Now().UTC(),
@@ -226,13 +312,13 @@ func HandlerFollow(s *state.State, cmd Command, user database.User) error {
 	return nil
 }
 
-func HandlerFollowing(s *state.State, cmd Command, user database.User) error {
+func HandlerFollowing(ctx context.Context, s *state.State, cmd Command, user database.User) error {
 	if len(cmd.Args) != 0 {
-		return fmt.Errorf("usage: %v", cmd.Name)
+		return cmd.usageError()
 	}
 
 	// No need to query for the user - it's passed in by middleware
-	userFeeds, err := s.DB.GetFeedFollowsByUser(context.Background(), user.ID)
+	userFeeds, err := s.DB.GetFeedFollowsByUser(ctx, user.ID)
 	if err != nil {
 		return fmt.Errorf("cannot get users feeds from database: %v", err)
 	}
@@ -245,16 +331,17 @@ func HandlerFollowing(s *state.State, cmd Command, user database.User) error {
 	return nil
 }
 
-func HandlerUnfollow(s *state.State, cmd Command, user database.User) error {
+func HandlerUnfollow(ctx context.Context, s *state.State, cmd Command, user database.User) error {
 	if len(cmd.Args) != 1 {
-		return fmt.Errorf("usage: %v <url>", cmd.Name)
+		return cmd.usageError()
 	}
 
 	url := cmd.Args[0]
 
-	err := s.DB.DeleteFeedFollow(context.Background(), database.DeleteFeedFollowParams{
-		UserID: user.ID,
-		Url:    url,
+	err := s.DB.DeleteFeedFollow(ctx, database.DeleteFeedFollowParams{
+		UserID:        user.ID,
+		Url:           url,
+		NormalizedUrl: normalizeURL(s, url),
 	})
 	if err != nil {
 		return fmt.Errorf("failed to unfollow: %v", err)
@@ -263,34 +350,115 @@ func HandlerUnfollow(s *state.State, cmd Command, user database.User) error {
 	return nil
 }
 
-func HandlerBrowse(s *state.State, cmd Command, user database.User) error {
-	var limit int32 = 2 // Default limit
-	if len(cmd.Args) > 0 {
-		parsedLimit, err := strconv.Atoi(cmd.Args[0])
-		if err != nil {
-			return fmt.Errorf("invalid limit: %v", err)
-		}
-		limit = int32(parsedLimit)
+func HandlerBrowse(ctx context.Context, s *state.State, cmd Command, user database.User) error {
+	if len(cmd.Args) > 1 {
+		return cmd.usageError()
 	}
 
-	posts, err := s.DB.GetPostsByUser(context.Background(), database.GetPostsByUserParams{
-		UserID: user.ID,
-		Limit:  limit,
+	prefs := loadPreferences(ctx, s, user.ID)
+	limit, err := parsePostLimit(cmd.Args, prefs.browseLimit)
+	if err != nil {
+		return err
+	}
+
+	posts, err := s.DB.GetPostsByUser(ctx, database.GetPostsByUserParams{
+		UserID:      user.ID,
+		Category:    categoryFilter(cmd),
+		Author:      authorFilter(cmd),
+		OldestFirst: prefs.oldestFirst,
+		Limit:       limit,
 	})
 	if err != nil {
 		return fmt.Errorf("error getting posts: %v", err)
 	}
 
 	if len(posts) == 0 {
+		if cmd.Flag("category") != "" || cmd.Flag("author") != "" {
+			fmt.Println("No posts match the given category or author.")
+			return nil
+		}
 		fmt.Println("No posts found. Follow some feeds first!")
 		return nil
 	}
 
+	printPosts(ctx, s, posts, prefs)
+	return nil
+}
+
+// HandlerSearch finds posts from followed feeds whose title or
+// description contains the given text
+func HandlerSearch(ctx context.Context, s *state.State, cmd Command, user database.User) error {
+	if len(cmd.Args) < 1 || len(cmd.Args) > 2 {
+		return cmd.usageError()
+	}
+
+	prefs := loadPreferences(ctx, s, user.ID)
+	limit, err := parsePostLimit(cmd.Args[1:], prefs.browseLimit)
+	if err != nil {
+		return err
+	}
+
+	rows, err := s.DB.SearchPostsByUser(ctx, database.SearchPostsByUserParams{
+		UserID:      user.ID,
+		Query:       cmd.Args[0],
+		Category:    categoryFilter(cmd),
+		Author:      authorFilter(cmd),
+		OldestFirst: prefs.oldestFirst,
+		Limit:       limit,
+	})
+	if err != nil {
+		return fmt.Errorf("error searching posts: %v", err)
+	}
+
+	if len(rows) == 0 {
+		fmt.Printf("No posts matching '%s'.\n", cmd.Args[0])
+		return nil
+	}
+
+	posts := make([]database.GetPostsByUserRow, len(rows))
+	for i, row := range rows {
+		posts[i] = database.GetPostsByUserRow(row)
+	}
+	printPosts(ctx, s, posts, prefs)
+	return nil
+}
+
+// parsePostLimit reads the optional limit argument of browse and search
+func parsePostLimit(args []string, limit int32) (int32, error) {
+	if len(args) > 0 {
+		parsedLimit, err := strconv.ParseInt(args[0], 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid limit: %v", err)
+		}
+		if parsedLimit < 1 {
+			return 0, fmt.Errorf("invalid limit: %d, must be at least 1", parsedLimit)
+		}
+		limit = int32(parsedLimit)
+	}
+	return limit, nil
+}
+
+// categoryFilter returns the --category flag, null when not given
+func categoryFilter(cmd Command) sql.NullString {
+	category := strings.TrimSpace(cmd.Flag("category"))
+	return sql.NullString{String: category, Valid: category != ""}
+}
+
+// authorFilter returns the --author flag, null when not given
+func authorFilter(cmd Command) sql.NullString {
+	author := strings.TrimSpace(cmd.Flag("author"))
+	return sql.NullString{String: author, Valid: author != ""}
+}
+
+func printPosts(ctx context.Context, s *state.State, posts []database.GetPostsByUserRow, prefs preferences) {
 	fmt.Printf("Found %d posts:\n\n", len(posts))
 	for i, post := range posts {
 		fmt.Printf("=== Post %d ===\n", i+1)
 		fmt.Printf("Title: %s\n", post.Title)
 		fmt.Printf("URL: %s\n", post.Url)
+		if post.Author.Valid {
+			fmt.Printf("By: %s\n", post.Author.String)
+		}
 
 		if post.Description.Valid {
 			// Only show a preview of the description to avoid too much text
@@ -302,17 +470,33 @@ func HandlerBrowse(s *state.State, cmd Command, user database.User) error {
 		}
 
 		if post.PublishedAt.Valid {
-			fmt.Printf("Published: %s\n", post.PublishedAt.Time.Format(time.RFC1123))
+			fmt.Printf("Published: %s\n", prefs.formatTime(post.PublishedAt.Time, time.RFC1123))
+		}
+		if post.RevisionCount > 0 {
+			versions := "versions"
+			if post.RevisionCount == 1 {
+				versions = "version"
+			}
+			fmt.Printf("Updated: %s (%d earlier %s, see 'diff')\n", prefs.formatTime(post.UpdatedAt, time.RFC1123), post.RevisionCount, versions)
 		}
-		fmt.Printf("Feed: %s\n\n", post.FeedName)
-	}
 
-	return nil
+		categories, err := s.DB.GetPostCategories(ctx, post.ID)
+		if err != nil {
+			slog.Warn("cannot get post categories", "post_id", post.ID, "error", err)
+		} else if len(categories) > 0 {
+			fmt.Printf("Categories: %s\n", strings.Join(categories, ", "))
+		}
+		fmt.Printf("Feed: %s\n", post.FeedName)
+		if others := alsoIn(ctx, s, post); len(others) > 0 {
+			fmt.Printf("Also in: %s\n", strings.Join(others, ", "))
+		}
+		fmt.Println()
+	}
 }
 
-func HandlerAgg(s *state.State, cmd Command) error {
+func HandlerAgg(ctx context.Context, s *state.State, cmd Command) error {
 	if len(cmd.Args) != 1 {
-		return fmt.Errorf("usage: %v <time_between_reqs>", cmd.Name)
+		return cmd.usageError()
 	}
 
 	timeStr := cmd.Args[0]
@@ -321,44 +505,197 @@ func HandlerAgg(s *state.State, cmd Command) error {
 		return fmt.Errorf("invalid duration format: %v", err)
 	}
 
-	fmt.Printf("Collecting feeds every %v\n", timeBetweenRequests)
+	drain, err := s.Cfg.Crawl.DrainPeriod()
+	if err != nil {
+		return err
+	}
+
+	leaseDuration, err := s.Cfg.Crawl.Lease()
+	if err != nil {
+		return err
+	}
+	lease := newFeedLease(leaseDuration)
+	bg := newBackground()
+
+	if metricsAddr := cmd.Flag("metrics-addr"); metricsAddr != "" {
+		if err := serveMetrics(ctx, s, metricsAddr); err != nil {
+			return err
+		}
+	}
+
+	slog.Info("collecting feeds", "interval", timeBetweenRequests, "worker", lease.owner)
 
 	// Run immediately and then on ticker
 	ticker := time.NewTicker(timeBetweenRequests)
-	// Immediate first run, then wait for ticker
-	for ; ; <-ticker.C {
-		if err := scrapeFeeds(s); err != nil {
-			fmt.Printf("Error scraping feeds: %v\n", err)
+	defer ticker.Stop()
+
+	for {
+		// A shutdown signal lets the current round finish within the drain period
+		workCtx, cancel := drainContext(ctx, drain)
+		if err := scrapeFeeds(workCtx, s, lease, bg); err != nil {
+			slog.Error("cannot scrape feeds", "error", err)
+		}
+		if ctx.Err() == nil {
+			if err := sendDueDigests(workCtx, s, lease); err != nil {
+				slog.Error("cannot send digests", "error", err)
+			}
+		}
+		cancel()
+
+		select {
+		case <-ctx.Done():
+			slog.Info("shutting down, waiting for pending background tasks", "drain_timeout", drain)
+			bg.Drain(drain)
+			slog.Info("stopped collecting feeds")
+			return nil
+		case <-ticker.C:
 		}
 	}
 }
-func scrapeFeeds(s *state.State) error {
-	ctx := context.Background()
 
-	// Get the next feed to fetch
-	feed, err := s.DB.GetNextFeedToFetch(ctx)
+// drainContext returns a context that outlives ctx by up to grace,
+// so work in flight can finish after ctx is cancelled
+func drainContext(ctx context.Context, grace time.Duration) (context.Context, context.CancelFunc) {
+	drainCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, func() {
+		time.AfterFunc(grace, cancel)
+	})
+	return drainCtx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// feedLease identifies this aggregator when claiming feeds and digests,
+// so several instances can share the work
+type feedLease struct {
+	owner    string
+	duration time.Duration
+}
+
+// newFeedLease names the worker after its host and process
+func newFeedLease(duration time.Duration) feedLease {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return feedLease{
+		owner:    fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		duration: duration,
+	}
+}
+
+func scrapeFeeds(ctx context.Context, s *state.State, lease feedLease, bg *background) error {
+	// Claim the next feed to fetch, skipping feeds leased by other workers
+	now := time.Now().UTC()
+	owner := sql.NullString{String: lease.owner, Valid: true}
+	feed, err := s.DB.ClaimNextFeed(ctx, database.ClaimNextFeedParams{
+		LeaseOwner:     owner,
+		LeaseExpiresAt: sql.NullTime{Time: now.Add(lease.duration), Valid: true},
+		Now:            sql.NullTime{Time: now, Valid: true},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		slog.Debug("no feed to fetch, there are none or all are leased by other workers")
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("error getting next feed to fetch: %v", err)
 	}
 
+	// Release the lease even when shutting down, so others need not wait for it to expire
+	defer func() {
+		err := s.DB.ReleaseFeedLease(context.WithoutCancel(ctx), database.ReleaseFeedLeaseParams{
+			ID:         feed.ID,
+			LeaseOwner: owner,
+		})
+		if err != nil {
+			slog.Error("cannot release feed lease", "feed_id", feed.ID, "error", err)
+		}
+	}()
+
 	// Mark it as fetched
 	err = s.DB.MarkFeedFetched(ctx, feed.ID)
 	if err != nil {
 		return fmt.Errorf("error marking feed as fetched: %v", err)
 	}
 
-	fmt.Printf("Fetching feed: %s (%s)\n", feed.Name, feed.Url)
+	logger := slog.With("feed_id", feed.ID, "url", redactURL(feed.Url))
+	logger.Debug("fetching feed", "feed", feed.Name)
 
 	// Fetch feed using URL
-	rssFeed, err := rssfeeds.FetchFeed(ctx, feed.Url)
+	start := time.Now()
+	rssFeed, err := fetchFeed(ctx, s, feed)
+	aggMetrics.recordFetch(start, err)
 	if err != nil {
-		return fmt.Errorf("error fetching feed: %v", err)
+		return fmt.Errorf("error fetching feed %s: %w", feed.ID, err)
+	}
+
+	logger.Info("fetched feed", "feed", feed.Name, "items", len(rssFeed.Channel.Item), "duration", time.Since(start))
+
+	if err := saveFeedMetadata(ctx, s, feed.ID, rssFeed); err != nil {
+		logger.Error("cannot save feed metadata", "error", err)
 	}
 
-	fmt.Printf("Found %d items in feed %s\n", len(rssFeed.Channel.Item), feed.Name)
+	savePosts(ctx, s, bg, feed, rssFeed.Channel.Item)
+
+	return nil
+}
+
+// saveFeedMetadata stores the channel's title, site link, description,
+// language, image and generator, replacing what the last fetch saw
+func saveFeedMetadata(ctx context.Context, s *state.State, feedID uuid.UUID, rssFeed *rssfeeds.RSSFeed) error {
+	channel := rssFeed.Channel
+	text := func(value string) sql.NullString {
+		value = strings.TrimSpace(value)
+		return sql.NullString{String: value, Valid: value != ""}
+	}
+
+	return s.DB.UpdateFeedMetadata(ctx, database.UpdateFeedMetadataParams{
+		ID:          feedID,
+		Title:       text(channel.Title),
+		SiteUrl:     text(channel.Link),
+		Description: text(channel.Description),
+		Language:    text(channel.Language),
+		ImageUrl:    text(rssFeed.ImageURL()),
+		Generator:   text(channel.Generator),
+	})
+}
+
+// savePosts stores feed items as posts, skipping ones that already exist.
+// It is shared by polling in scrapeFeeds and content pushed over WebSub.
+// Webhooks and canonical URL lookups for new posts are queued on bg
+func savePosts(ctx context.Context, s *state.State, bg *background, feed database.Feed, items []rssfeeds.RSSItem) {
+	logger := slog.With("feed_id", feed.ID)
+
+	// Look up webhooks once per feed so each new post can be pushed out
+	hooks, err := s.DB.GetWebhooksForFeed(ctx, feed.ID)
+	if err != nil {
+		logger.Error("cannot get webhooks for feed", "error", err)
+	}
+
+	// Save posts to database. A feed listing the same URL twice, for
+	// instance with different tracking parameters, only counts the first
+	seen := make(map[string]bool, len(items))
+	var saved []database.Post
+	for _, item := range items {
+		normalizedURL := normalizeURL(s, item.Link)
+		if seen[normalizedURL] {
+			aggMetrics.duplicatesSkipped.Inc()
+			continue
+		}
+		seen[normalizedURL] = true
+
+		// Items saved before are only checked for changes
+		existing, err := postByURL(ctx, s, item.Link)
+		if err == nil {
+			updateExistingPost(ctx, s, logger, feed, existing, item)
+			continue
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			logger.Error("cannot look up post", "url", item.Link, "error", err)
+			continue
+		}
 
-	// Save posts to database
-	for _, item := range rssFeed.Channel.Item {
 		// Parse the published date
 		var publishedAt sql.NullTime
 		if item.PubDate != "" {
@@ -368,35 +705,157 @@ func scrapeFeeds(s *state.State) error {
 				publishedAt.Time = parsedTime
 				publishedAt.Valid = true
 			} else {
-				fmt.Printf("Warning: Could not parse date '%s': %v\n", item.PubDate, err)
+				aggMetrics.dateParseFailures.Inc()
+				logger.Warn("cannot parse publication date", "url", item.Link, "pub_date", item.PubDate)
 			}
 		}
 
+		byline := item.Byline()
+
+		// Group the post with the same story from other feeds
+		postID := uuid.New()
+		storyID := findStory(ctx, s, feed.ID, postID, normalizedURL, item.Title)
+
 		// Create post in database
-		_, err := s.DB.CreatePost(ctx, database.CreatePostParams{
-			ID:          uuid.New(),
-			CreatedAt:   time.Now().UTC(),
-			UpdatedAt:   time.Now().UTC(),
-			Title:       item.Title,
-			Url:         item.Link,
-			Description: sql.NullString{String: item.Description, Valid: item.Description != ""},
-			PublishedAt: publishedAt,
-			FeedID:      feed.ID,
+		post, err := s.DB.CreatePost(ctx, database.CreatePostParams{
+			ID:            postID,
+			CreatedAt:     time.Now().UTC(),
+			UpdatedAt:     time.Now().UTC(),
+			Title:         item.Title,
+			Url:           item.Link,
+			Description:   sql.NullString{String: item.Description, Valid: item.Description != ""},
+			PublishedAt:   publishedAt,
+			FeedID:        feed.ID,
+			Author:        sql.NullString{String: byline, Valid: byline != ""},
+			StoryID:       uuid.NullUUID{UUID: storyID, Valid: true},
+			NormalizedUrl: normalizedURL,
 		})
 
 		if err != nil {
-			// If it's a duplicate URL, just ignore the error
-			if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+			// If it's a duplicate URL saved meanwhile, just ignore the error
+			if isDuplicateError(err) {
+				aggMetrics.duplicatesSkipped.Inc()
 				continue
 			}
 			// Otherwise log the error
-			fmt.Printf("Error saving post '%s': %v\n", item.Title, err)
+			logger.Error("cannot save post", "url", item.Link, "error", err)
 		} else {
-			fmt.Printf("Saved post: %s\n", item.Title)
+			aggMetrics.postsInserted.Inc()
+			logger.Info("saved post", "post_id", post.ID, "url", post.Url)
+			saveCategories(ctx, s, post.ID, item.CategoryNames())
+			notifyWebhooks(s, bg, hooks, feed, post)
+			saved = append(saved, post)
 		}
 	}
 
-	return nil
+	queueCanonicalResolution(ctx, s, bg, feed, saved)
+}
+
+// queueCanonicalResolution resolves the canonical URLs of new posts in
+// the background, since each takes a request to the article
+func queueCanonicalResolution(ctx context.Context, s *state.State, bg *background, feed database.Feed, posts []database.Post) {
+	if len(posts) == 0 || s.Cfg.Crawl.SkipCanonical || s.Fetcher == nil {
+		return
+	}
+
+	opts, err := loadFetchOptions(ctx, s, feed)
+	if err != nil {
+		slog.Error("cannot resolve canonical urls", "feed_id", feed.ID, "error", err)
+		return
+	}
+	for _, post := range posts {
+		bg.canonical.Go(func(ctx context.Context) {
+			resolvePostCanonical(ctx, s, feed, opts, post)
+		})
+	}
+}
+
+// updateExistingPost handles an item that is already saved. Edits made
+// by the post's own feed are kept as revisions, while another feed
+// carrying the same URL is recorded for "also in"
+func updateExistingPost(ctx context.Context, s *state.State, logger *slog.Logger, feed database.Feed, post database.Post, item rssfeeds.RSSItem) {
+	if post.FeedID != feed.ID {
+		err := s.DB.AddPostFeed(ctx, database.AddPostFeedParams{
+			PostID: post.ID,
+			FeedID: feed.ID,
+			SeenAt: time.Now().UTC(),
+		})
+		if err != nil {
+			logger.Error("cannot record feed carrying post", "post_id", post.ID, "error", err)
+		}
+		aggMetrics.duplicatesSkipped.Inc()
+		return
+	}
+
+	revised, err := revisePost(ctx, s, post, item)
+	switch {
+	case err != nil:
+		logger.Error("cannot update changed post", "url", item.Link, "error", err)
+	case revised:
+		aggMetrics.postsUpdated.Inc()
+		logger.Info("post changed, kept previous version", "url", item.Link)
+	default:
+		aggMetrics.duplicatesSkipped.Inc()
+	}
+}
+
+// revisePost updates a post whose title or description the feed
+// changed, saving the previous version as a revision
+func revisePost(ctx context.Context, s *state.State, post database.Post, item rssfeeds.RSSItem) (bool, error) {
+	if post.Title == item.Title && post.Description.String == item.Description {
+		return false, nil
+	}
+
+	now := time.Now().UTC()
+	err := s.DB.CreatePostRevision(ctx, database.CreatePostRevisionParams{
+		ID:          uuid.New(),
+		PostID:      post.ID,
+		ReplacedAt:  now,
+		Title:       post.Title,
+		Description: post.Description,
+	})
+	if err != nil {
+		return false, fmt.Errorf("cannot save previous version: %v", err)
+	}
+
+	err = s.DB.UpdatePostContent(ctx, database.UpdatePostContentParams{
+		ID:          post.ID,
+		Title:       item.Title,
+		Description: sql.NullString{String: item.Description, Valid: item.Description != ""},
+		UpdatedAt:   now,
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// saveCategories links a new post to its categories, creating the
+// categories not seen before
+func saveCategories(ctx context.Context, s *state.State, postID uuid.UUID, names []string) {
+	for _, name := range names {
+		category, err := s.DB.UpsertCategory(ctx, database.UpsertCategoryParams{
+			ID:   uuid.New(),
+			Name: name,
+		})
+		if err == nil {
+			err = s.DB.AddPostCategory(ctx, database.AddPostCategoryParams{
+				PostID:     postID,
+				CategoryID: category.ID,
+			})
+		}
+		if err != nil {
+			slog.Error("cannot save post category", "post_id", postID, "category", name, "error", err)
+		}
+	}
+}
+
+// isDuplicateError reports whether err is a unique constraint violation,
+// as worded by Postgres or SQLite
+func isDuplicateError(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "duplicate key value violates unique constraint") ||
+		strings.Contains(msg, "UNIQUE constraint failed")
 }
 
 // Helper function to parse different time formats
@@ -0,0 +1,39 @@
+package commands
+
+import "testing"
+
+func TestParsePostLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    int32
+		wantErr bool
+	}{
+		{name: "default", args: nil, want: 2},
+		{name: "given", args: []string{"10"}, want: 10},
+		{name: "extra arguments ignored", args: []string{"5", "go"}, want: 5},
+		{name: "one", args: []string{"1"}, want: 1},
+		{name: "zero", args: []string{"0"}, wantErr: true},
+		{name: "negative", args: []string{"-3"}, wantErr: true},
+		{name: "not a number", args: []string{"ten"}, wantErr: true},
+		{name: "too large", args: []string{"4294967296"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePostLimit(tt.args, 2)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parsePostLimit(%q) = %d, want an error", tt.args, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parsePostLimit(%q) returned error: %v", tt.args, err)
+			}
+			if got != tt.want {
+				t.Errorf("parsePostLimit(%q) = %d, want %d", tt.args, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/twomotive/GoFlux/internal/config"
+	"github.com/twomotive/GoFlux/internal/state"
+)
+
+// initPrompts are the settings asked for by 'config init', in order
+var initPrompts = []struct {
+	key      string
+	question string
+}{
+	{"db_url", "Database URL (postgres://... or sqlite:///path/to/goflux.db)"},
+	{"smtp.host", "SMTP host for digests (leave empty to skip)"},
+	{"smtp.from", "Digest sender address"},
+}
+
+// HandlerConfig reads and writes the layered configuration
+func HandlerConfig(ctx context.Context, s *state.State, cmd Command) error {
+	if len(cmd.Args) < 1 {
+		return cmd.usageError()
+	}
+
+	switch cmd.Args[0] {
+	case "get":
+		if len(cmd.Args) == 2 {
+			value, err := s.Cfg.Get(cmd.Args[1])
+			if err != nil {
+				return err
+			}
+			fmt.Println(value)
+			return nil
+		}
+		if len(cmd.Args) != 1 {
+			return cmd.usageError()
+		}
+		for _, key := range config.Keys() {
+			value, err := s.Cfg.Get(key)
+			if err != nil {
+				return err
+			}
+			if key == "secret_key" || key == "session_token" || key == "token" || key == "smtp.password" {
+				value = maskSecret(value)
+			}
+			fmt.Printf("%-25s %s\n", key, value)
+		}
+	case "set":
+		if len(cmd.Args) != 3 {
+			return cmd.usageError()
+		}
+		if err := s.Cfg.Set(cmd.Args[1], cmd.Args[2]); err != nil {
+			return err
+		}
+		fmt.Printf("Set %s in %s\n", cmd.Args[1], s.Cfg.Path())
+	case "init":
+		if len(cmd.Args) != 1 {
+			return cmd.usageError()
+		}
+		if s.Cfg.Exists() {
+			return fmt.Errorf("config file %s already exists, use 'config set' to change it", s.Cfg.Path())
+		}
+		settings, err := promptSettings(os.Stdin, os.Stdout)
+		if err != nil {
+			return err
+		}
+		if err := s.Cfg.Init(settings); err != nil {
+			return err
+		}
+		fmt.Printf("Wrote %s\n", s.Cfg.Path())
+	case "path":
+		fmt.Println(s.Cfg.Path())
+		if profile := s.Cfg.Profile(); profile != "" {
+			fmt.Printf("Profile: %s\n", profile)
+		}
+	default:
+		return cmd.usageError()
+	}
+
+	return nil
+}
+
+// promptSettings asks for the initial settings, skipping empty answers
+func promptSettings(in io.Reader, out io.Writer) (map[string]string, error) {
+	reader := bufio.NewReader(in)
+	settings := make(map[string]string)
+
+	for _, prompt := range initPrompts {
+		// Without an SMTP host the sender address is meaningless
+		if prompt.key == "smtp.from" && settings["smtp.host"] == "" {
+			continue
+		}
+
+		fmt.Fprintf(out, "%s: ", prompt.question)
+		answer, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("cannot read answer: %v", err)
+		}
+		if answer = strings.TrimSpace(answer); answer != "" {
+			settings[prompt.key] = answer
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+
+	if settings["db_url"] == "" {
+		return nil, fmt.Errorf("a database URL is required")
+	}
+
+	return settings, nil
+}
+
+func maskSecret(value string) string {
+	if value == "" {
+		return ""
+	}
+	return "********"
+}
@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/twomotive/GoFlux/internal/database"
+	"github.com/twomotive/GoFlux/internal/state"
+	"github.com/twomotive/GoFlux/internal/textdiff"
+)
+
+// HandlerDiff shows how a post changed each time its feed edited it
+func HandlerDiff(ctx context.Context, s *state.State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 1 {
+		return cmd.usageError()
+	}
+
+	post, err := lookupPost(ctx, s, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+
+	revisions, err := s.DB.GetPostRevisions(ctx, post.ID)
+	if err != nil {
+		return fmt.Errorf("cannot get post revisions: %v", err)
+	}
+
+	prefs := loadPreferences(ctx, s, user.ID)
+	fmt.Printf("Title: %s\nURL: %s\nFirst seen: %s\n", post.Title, post.Url, prefs.formatTime(post.CreatedAt, time.RFC1123))
+	if len(revisions) == 0 {
+		fmt.Println("\nThe post has not changed since it was first seen.")
+		return nil
+	}
+
+	// Compare each version with the one that replaced it
+	for i, revision := range revisions {
+		nextTitle, nextDescription := post.Title, post.Description.String
+		if i+1 < len(revisions) {
+			nextTitle, nextDescription = revisions[i+1].Title, revisions[i+1].Description.String
+		}
+
+		fmt.Printf("\n=== Changed %s ===\n", prefs.formatTime(revision.ReplacedAt, time.RFC1123))
+		if title := textdiff.Words(revision.Title, nextTitle); textdiff.Changed(title) {
+			fmt.Printf("Title: %s\n", textdiff.Format(title))
+		}
+		if description := textdiff.Words(revision.Description.String, nextDescription); textdiff.Changed(description) {
+			fmt.Printf("Description: %s\n", textdiff.Format(description))
+		}
+	}
+	return nil
+}
+
+// lookupPost finds a post by its URL or ID
+func lookupPost(ctx context.Context, s *state.State, ref string) (database.Post, error) {
+	post, err := postByURL(ctx, s, ref)
+	if errors.Is(err, sql.ErrNoRows) {
+		if id, parseErr := uuid.Parse(ref); parseErr == nil {
+			post, err = s.DB.GetPostByID(ctx, id)
+		}
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.Post{}, fmt.Errorf("no post with URL or ID '%s'", ref)
+	}
+	if err != nil {
+		return database.Post{}, fmt.Errorf("cannot get post: %v", err)
+	}
+	return post, nil
+}
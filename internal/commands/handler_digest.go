@@ -0,0 +1,282 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/twomotive/GoFlux/internal/database"
+	"github.com/twomotive/GoFlux/internal/digest"
+	"github.com/twomotive/GoFlux/internal/state"
+)
+
+// HandlerDigest manages the current user's email digest subscription
+func HandlerDigest(ctx context.Context, s *state.State, cmd Command, user database.User) error {
+	if len(cmd.Args) == 0 {
+		return cmd.usageError()
+	}
+
+	switch cmd.Args[0] {
+	case "subscribe":
+		return digestSubscribe(ctx, s, cmd.Args[1:], user)
+	case "unsubscribe":
+		return digestUnsubscribe(ctx, s, user)
+	case "status":
+		return digestStatus(ctx, s, user)
+	case "preview":
+		return digestPreview(ctx, s, user)
+	case "send":
+		return digestSend(ctx, s, user)
+	default:
+		return cmd.usageError()
+	}
+}
+
+func digestSubscribe(ctx context.Context, s *state.State, args []string, user database.User) error {
+	if len(args) < 1 || len(args) > 2 {
+		return fmt.Errorf("usage: digest subscribe <email> [daily|weekly]")
+	}
+
+	email := args[0]
+	frequency := "daily"
+	if len(args) == 2 {
+		frequency = args[1]
+	}
+	if frequency != "daily" && frequency != "weekly" {
+		return fmt.Errorf("invalid frequency '%s', expected daily or weekly", frequency)
+	}
+
+	sub, err := s.DB.UpsertDigestSubscription(ctx, database.UpsertDigestSubscriptionParams{
+		UserID:    user.ID,
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+		Email:     email,
+		Frequency: frequency,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot save digest subscription: %v", err)
+	}
+
+	fmt.Printf("Subscribed %s to a %s digest\n", sub.Email, sub.Frequency)
+	return nil
+}
+
+func digestUnsubscribe(ctx context.Context, s *state.State, user database.User) error {
+	deleted, err := s.DB.DeleteDigestSubscription(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("cannot remove digest subscription: %v", err)
+	}
+	if deleted == 0 {
+		return fmt.Errorf("no digest subscription found")
+	}
+
+	fmt.Println("Unsubscribed from digests")
+	return nil
+}
+
+func digestStatus(ctx context.Context, s *state.State, user database.User) error {
+	sub, err := s.DB.GetDigestSubscriptionByUser(ctx, user.ID)
+	if err == sql.ErrNoRows {
+		fmt.Println("Not subscribed to digests.")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("cannot get digest subscription: %v", err)
+	}
+
+	fmt.Printf("Email:     %s\n", sub.Email)
+	fmt.Printf("Frequency: %s\n", sub.Frequency)
+	if sub.LastSentAt.Valid {
+		prefs := loadPreferences(ctx, s, user.ID)
+		fmt.Printf("Last sent: %s\n", prefs.formatTime(sub.LastSentAt.Time, time.RFC1123))
+	} else {
+		fmt.Println("Last sent: never")
+	}
+	return nil
+}
+
+func digestPreview(ctx context.Context, s *state.State, user database.User) error {
+	sub, err := s.DB.GetDigestSubscriptionByUser(ctx, user.ID)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("cannot get digest subscription: %v", err)
+	}
+
+	d, err := buildDigest(ctx, s, user.ID, user.Name, sub.Frequency, sub.LastSentAt)
+	if err != nil {
+		return err
+	}
+	if d == nil {
+		fmt.Println("No new posts for a digest.")
+		return nil
+	}
+
+	fmt.Printf("Subject: %s\n\n%s", d.Subject, d.Text)
+	return nil
+}
+
+func digestSend(ctx context.Context, s *state.State, user database.User) error {
+	sub, err := s.DB.GetDigestSubscriptionByUser(ctx, user.ID)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("not subscribed, run 'digest subscribe <email>' first")
+	}
+	if err != nil {
+		return fmt.Errorf("cannot get digest subscription: %v", err)
+	}
+
+	sent, err := deliverDigest(ctx, s, user.ID, user.Name, sub.Email, sub.Frequency, sub.LastSentAt)
+	if err != nil {
+		return err
+	}
+	if !sent {
+		fmt.Println("No new posts for a digest.")
+		return nil
+	}
+
+	fmt.Printf("Digest sent to %s\n", sub.Email)
+	return nil
+}
+
+// sendDueDigests mails every subscription whose period has elapsed. Each
+// is leased first, so aggregators sharing the database never send the
+// same digest twice
+func sendDueDigests(ctx context.Context, s *state.State, lease feedLease) error {
+	owner := sql.NullString{String: lease.owner, Valid: true}
+	for ctx.Err() == nil {
+		now := time.Now().UTC()
+		sub, err := s.DB.ClaimNextDigest(ctx, database.ClaimNextDigestParams{
+			LeaseOwner:     owner,
+			LeaseExpiresAt: sql.NullTime{Time: now.Add(lease.duration), Valid: true},
+			Now:            now,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error getting due digests: %v", err)
+		}
+
+		// A failed digest keeps its lease, so it is retried once the lease
+		// expires rather than claimed again in this round
+		if err := sendLeasedDigest(ctx, s, sub); err != nil {
+			slog.Error("cannot send digest", "user_id", sub.UserID, "error", err)
+			continue
+		}
+
+		err = s.DB.ReleaseDigestLease(context.WithoutCancel(ctx), database.ReleaseDigestLeaseParams{
+			UserID:     sub.UserID,
+			LeaseOwner: owner,
+		})
+		if err != nil {
+			slog.Error("cannot release digest lease", "user_id", sub.UserID, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// sendLeasedDigest mails the digest of a claimed subscription
+func sendLeasedDigest(ctx context.Context, s *state.State, sub database.DigestSubscription) error {
+	user, err := s.DB.GetUserByID(ctx, sub.UserID)
+	if err != nil {
+		return fmt.Errorf("cannot get user: %v", err)
+	}
+
+	sent, err := deliverDigest(ctx, s, sub.UserID, user.Name, sub.Email, sub.Frequency, sub.LastSentAt)
+	if err != nil {
+		return err
+	}
+	if sent {
+		slog.Info("sent digest", "user_id", sub.UserID, "frequency", sub.Frequency)
+	}
+	return nil
+}
+
+// deliverDigest builds and mails a digest, then records it as sent.
+// It reports false when there was nothing new to send
+func deliverDigest(ctx context.Context, s *state.State, userID uuid.UUID, userName, email, frequency string, lastSentAt sql.NullTime) (bool, error) {
+	now := time.Now().UTC()
+
+	d, err := buildDigest(ctx, s, userID, userName, frequency, lastSentAt)
+	if err != nil {
+		return false, err
+	}
+
+	if d != nil {
+		if err := digest.Send(ctx, s.Cfg.SMTP, email, d); err != nil {
+			return false, err
+		}
+	}
+
+	// Mark empty periods as sent too, so the next digest covers only new posts
+	err = s.DB.MarkDigestSent(ctx, database.MarkDigestSentParams{
+		UserID:     userID,
+		LastSentAt: sql.NullTime{Time: now, Valid: true},
+	})
+	if err != nil {
+		return false, fmt.Errorf("digest sent but couldn't record it: %v", err)
+	}
+
+	return d != nil, nil
+}
+
+// buildDigest collects the posts since the last digest grouped by feed.
+// It returns nil when there are no new posts
+func buildDigest(ctx context.Context, s *state.State, userID uuid.UUID, userName, frequency string, lastSentAt sql.NullTime) (*digest.Digest, error) {
+	since := time.Now().UTC().Add(-digestPeriod(frequency))
+	if lastSentAt.Valid {
+		since = lastSentAt.Time
+	}
+
+	posts, err := s.DB.GetPostsForDigest(ctx, database.GetPostsForDigestParams{
+		UserID:    userID,
+		CreatedAt: since,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot get posts for digest: %v", err)
+	}
+	if len(posts) == 0 {
+		return nil, nil
+	}
+
+	// Posts are ordered by feed, so a new section starts whenever the feed
+	// changes. Feeds are told apart by ID, since two may share a name
+	var sections []digest.Section
+	var sectionFeed uuid.UUID
+	for _, post := range posts {
+		if len(sections) == 0 || post.FeedID != sectionFeed {
+			sections = append(sections, digest.Section{Feed: post.FeedName})
+			sectionFeed = post.FeedID
+		}
+
+		entry := digest.Post{
+			Title:       post.Title,
+			URL:         post.Url,
+			Description: post.Description.String,
+		}
+		if post.PublishedAt.Valid {
+			entry.PublishedAt = &post.PublishedAt.Time
+		}
+
+		current := &sections[len(sections)-1]
+		current.Posts = append(current.Posts, entry)
+	}
+
+	prefs := loadPreferences(ctx, s, userID)
+	return digest.Build(userName, since, sections, digest.Options{
+		Location:   prefs.location,
+		DateLayout: prefs.dateLayout,
+		Language:   prefs.language,
+	})
+}
+
+// digestPeriod returns how much time a digest of the given frequency covers
+func digestPeriod(frequency string) time.Duration {
+	if frequency == "weekly" {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
@@ -0,0 +1,272 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/twomotive/GoFlux/internal/auth"
+	"github.com/twomotive/GoFlux/internal/config"
+	"github.com/twomotive/GoFlux/internal/database"
+	"github.com/twomotive/GoFlux/internal/rssfeeds"
+	"github.com/twomotive/GoFlux/internal/secrets"
+	"github.com/twomotive/GoFlux/internal/state"
+)
+
+// HandlerFeedHTTP manages per-feed HTTP settings for feeds the user added,
+// or for any feed when run by an admin.
+// Settings are stored encrypted with the key from the config file
+func HandlerFeedHTTP(ctx context.Context, s *state.State, cmd Command, user database.User) error {
+	if len(cmd.Args) < 2 {
+		return cmd.usageError()
+	}
+
+	feed, err := feedByURL(ctx, s, cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("cannot get feed from database: %v", err)
+	}
+	if feed.UserID != user.ID && user.Role != auth.RoleAdmin {
+		return fmt.Errorf("only the user who added '%s' or an admin can change its HTTP settings", feed.Name)
+	}
+
+	opts, err := loadFetchOptions(ctx, s, feed)
+	if err != nil {
+		return err
+	}
+
+	setting, args := cmd.Args[1], cmd.Args[2:]
+	switch setting {
+	case "show":
+		printFetchOptions(feed, opts)
+		return nil
+	case "clear":
+		if err := s.DB.DeleteFeedHTTPSettings(ctx, feed.ID); err != nil {
+			return fmt.Errorf("cannot clear HTTP settings: %v", err)
+		}
+		fmt.Printf("HTTP settings cleared for '%s'\n", feed.Name)
+		return nil
+	case "auth":
+		err = applyAuthSetting(&opts, args)
+	case "header":
+		opts.Headers, err = applyMapSetting(opts.Headers, "header", args)
+	case "cookie":
+		opts.Cookies, err = applyMapSetting(opts.Cookies, "cookie", args)
+	case "user-agent":
+		if len(args) > 1 {
+			return fmt.Errorf("usage: feedhttp <feed_url> user-agent [value]")
+		}
+		opts.UserAgent = ""
+		if len(args) == 1 {
+			opts.UserAgent = args[0]
+		}
+	case "proxy":
+		if len(args) > 1 {
+			return fmt.Errorf("usage: feedhttp <feed_url> proxy [url]")
+		}
+		opts.ProxyURL = ""
+		if len(args) == 1 {
+			if _, err := url.Parse(args[0]); err != nil {
+				return fmt.Errorf("invalid proxy url: %v", err)
+			}
+			opts.ProxyURL = args[0]
+		}
+	default:
+		return cmd.usageError()
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := saveFetchOptions(ctx, s, feed, opts); err != nil {
+		return err
+	}
+
+	fmt.Printf("HTTP settings updated for '%s'\n", feed.Name)
+	return nil
+}
+
+func applyAuthSetting(opts *rssfeeds.FetchOptions, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: feedhttp <feed_url> auth basic <username> <password> | bearer <token> | none")
+	}
+
+	opts.Username, opts.Password, opts.BearerToken = "", "", ""
+
+	switch args[0] {
+	case "basic":
+		if len(args) != 3 {
+			return fmt.Errorf("usage: feedhttp <feed_url> auth basic <username> <password>")
+		}
+		opts.Username, opts.Password = args[1], args[2]
+	case "bearer":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: feedhttp <feed_url> auth bearer <token>")
+		}
+		opts.BearerToken = args[1]
+	case "none":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: feedhttp <feed_url> auth none")
+		}
+	default:
+		return fmt.Errorf("unknown auth type '%s', expected basic, bearer or none", args[0])
+	}
+
+	return nil
+}
+
+func applyMapSetting(values map[string]string, setting string, args []string) (map[string]string, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, fmt.Errorf("usage: feedhttp <feed_url> %s <name> [value]", setting)
+	}
+
+	if len(args) == 1 {
+		delete(values, args[0])
+		return values, nil
+	}
+
+	if values == nil {
+		values = make(map[string]string)
+	}
+	values[args[0]] = args[1]
+	return values, nil
+}
+
+func printFetchOptions(feed database.Feed, opts rssfeeds.FetchOptions) {
+	fmt.Printf("HTTP settings for '%s'\n", feed.Name)
+
+	switch {
+	case opts.BearerToken != "":
+		fmt.Println("  auth:       bearer ********")
+	case opts.Username != "":
+		fmt.Printf("  auth:       basic %s:********\n", opts.Username)
+	default:
+		fmt.Println("  auth:       none")
+	}
+
+	if opts.UserAgent != "" {
+		fmt.Printf("  user-agent: %s\n", opts.UserAgent)
+	}
+	if opts.ProxyURL != "" {
+		fmt.Printf("  proxy:      %s\n", redactURL(opts.ProxyURL))
+	}
+	for _, name := range sortedKeys(opts.Headers) {
+		fmt.Printf("  header:     %s: ********\n", name)
+	}
+	for _, name := range sortedKeys(opts.Cookies) {
+		fmt.Printf("  cookie:     %s=********\n", name)
+	}
+}
+
+// loadFetchOptions decrypts the HTTP settings of a feed.
+// Feeds without settings get the zero value
+func loadFetchOptions(ctx context.Context, s *state.State, feed database.Feed) (rssfeeds.FetchOptions, error) {
+	var opts rssfeeds.FetchOptions
+
+	settings, err := s.DB.GetFeedHTTPSettings(ctx, feed.ID)
+	if err == sql.ErrNoRows {
+		return opts, nil
+	}
+	if err != nil {
+		return opts, fmt.Errorf("cannot get HTTP settings for feed %s: %v", feed.Name, err)
+	}
+
+	key, err := s.Cfg.EncryptionKey()
+	if errors.Is(err, config.ErrNoSecretKey) {
+		return opts, fmt.Errorf("feed %s has encrypted HTTP settings but %v, copy secret_key from the config they were saved with", feed.Name, err)
+	}
+	if err != nil {
+		return opts, err
+	}
+
+	plaintext, err := secrets.Decrypt(key, settings.EncryptedOptions)
+	if err != nil {
+		return opts, fmt.Errorf("cannot decrypt HTTP settings for feed %s: %v", feed.Name, err)
+	}
+
+	if err := json.Unmarshal(plaintext, &opts); err != nil {
+		return opts, fmt.Errorf("JSON Unmarshal error: %v", err)
+	}
+
+	return opts, nil
+}
+
+// saveFetchOptions encrypts and stores the HTTP settings of a feed
+func saveFetchOptions(ctx context.Context, s *state.State, feed database.Feed, opts rssfeeds.FetchOptions) error {
+	plaintext, err := json.Marshal(opts)
+	if err != nil {
+		return fmt.Errorf("JSON Marshal error: %v", err)
+	}
+
+	key, err := encryptionKeyForSave(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	encrypted, err := secrets.Encrypt(key, plaintext)
+	if err != nil {
+		return err
+	}
+
+	err = s.DB.UpsertFeedHTTPSettings(ctx, database.UpsertFeedHTTPSettingsParams{
+		FeedID:           feed.ID,
+		CreatedAt:        time.Now().UTC(),
+		UpdatedAt:        time.Now().UTC(),
+		EncryptedOptions: encrypted,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot save HTTP settings: %v", err)
+	}
+
+	return nil
+}
+
+// encryptionKeyForSave returns the configured key, generating one only
+// when no encrypted settings exist yet that a new key could not read
+func encryptionKeyForSave(ctx context.Context, s *state.State) ([]byte, error) {
+	key, err := s.Cfg.EncryptionKey()
+	if !errors.Is(err, config.ErrNoSecretKey) {
+		return key, err
+	}
+
+	count, err := s.DB.CountFeedHTTPSettings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot count HTTP settings: %v", err)
+	}
+	if count > 0 {
+		return nil, fmt.Errorf("encrypted HTTP settings exist but %v, copy secret_key from the config they were saved with", config.ErrNoSecretKey)
+	}
+	return s.Cfg.NewEncryptionKey()
+}
+
+// fetchFeed fetches a stored feed with its HTTP settings applied,
+// going through the shared polite fetcher
+func fetchFeed(ctx context.Context, s *state.State, feed database.Feed) (*rssfeeds.RSSFeed, error) {
+	opts, err := loadFetchOptions(ctx, s, feed)
+	if err != nil {
+		return nil, err
+	}
+	return s.Fetcher.Fetch(ctx, feed.Url, opts)
+}
+
+// redactURL hides the password of a URL with user info
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return u.Redacted()
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,125 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/twomotive/GoFlux/internal/auth"
+	"github.com/twomotive/GoFlux/internal/state"
+)
+
+// HandlerMigrate applies or rolls back the embedded schema migrations
+func HandlerMigrate(ctx context.Context, s *state.State, cmd Command) error {
+	if len(cmd.Args) != 1 {
+		return cmd.usageError()
+	}
+
+	s.Migrator.BeforeUp(normalizedURLIndexVersion, func(ctx context.Context) error {
+		return normalizeStoredURLs(ctx, s)
+	})
+
+	switch cmd.Args[0] {
+	case "up":
+		applied, err := s.Migrator.Up(ctx)
+		for _, migration := range applied {
+			fmt.Printf("Applied %s\n", migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println("Database is already up to date.")
+		}
+	case "down":
+		if err := authorizeRollback(ctx, s, cmd); err != nil {
+			return err
+		}
+		migration, err := s.Migrator.Down(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Rolled back %s\n", migration.Name)
+	case "redo":
+		if err := authorizeRollback(ctx, s, cmd); err != nil {
+			return err
+		}
+		migration, err := s.Migrator.Redo(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Reapplied %s\n", migration.Name)
+	case "status":
+		statuses, err := s.Migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		prefs := migratePreferences(ctx, s)
+		fmt.Printf("%-25s %s\n", "Applied At", "Migration")
+		for _, status := range statuses {
+			appliedAt := "Pending"
+			if status.AppliedAt.Valid {
+				appliedAt = prefs.formatTime(status.AppliedAt.Time, time.DateTime)
+			}
+			fmt.Printf("%-25s %s\n", appliedAt, status.Migration.Name)
+		}
+	default:
+		return cmd.usageError()
+	}
+
+	return nil
+}
+
+const (
+	// userRolesVersion is the migration adding user roles. Logins cannot
+	// be checked below it, as the account queries expect its columns
+	userRolesVersion = 12
+	// userSettingsVersion is the migration adding user settings
+	userSettingsVersion = 18
+)
+
+// migratePreferences returns the logged in user's preferences, or the
+// defaults while the schema is too old to hold settings
+func migratePreferences(ctx context.Context, s *state.State) preferences {
+	current, err := s.Migrator.Current(ctx)
+	if err != nil || current < userSettingsVersion {
+		return defaultPreferences()
+	}
+	return currentPreferences(ctx, s)
+}
+
+// authorizeRollback guards commands that roll back a migration, which
+// drops tables or columns with their data. Once accounts with roles
+// exist only an admin may do it, and the user always has to confirm
+func authorizeRollback(ctx context.Context, s *state.State, cmd Command) error {
+	current, err := s.Migrator.Current(ctx)
+	if err != nil {
+		return err
+	}
+
+	count, err := s.DB.CountUsers(ctx)
+	if current >= userRolesVersion && err == nil && count > 0 {
+		user, _, err := authenticate(ctx, s)
+		if err != nil {
+			return fmt.Errorf("rolling back migrations requires an admin login: %v", err)
+		}
+		if user.Role != auth.RoleAdmin {
+			return fmt.Errorf("'%s %s' requires an admin account", cmd.Name, cmd.Args[0])
+		}
+	}
+
+	statuses, err := s.Migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+	name := ""
+	for _, status := range statuses {
+		if status.AppliedAt.Valid {
+			name = status.Migration.Name
+		}
+	}
+	if name == "" {
+		return fmt.Errorf("no migration to roll back")
+	}
+	return confirm(fmt.Sprintf("This rolls back %s and may delete the data it stores.", name), cmd.Bool("yes"))
+}
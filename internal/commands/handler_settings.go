@@ -0,0 +1,306 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/twomotive/GoFlux/internal/database"
+	"github.com/twomotive/GoFlux/internal/state"
+)
+
+// defaultBrowseLimit is how many posts browse and search show by default
+const defaultBrowseLimit = 2
+
+// settingKeys are the per-user settings, in the order they are shown
+var settingKeys = []string{"timezone", "date_format", "browse_limit", "sort", "language"}
+
+// dateFormats name common layouts. Any other date_format value is used
+// as a Go time layout
+var dateFormats = map[string]string{
+	"rfc1123":  time.RFC1123,
+	"rfc3339":  time.RFC3339,
+	"datetime": "2006-01-02 15:04",
+	"date":     time.DateOnly,
+	"us":       "Jan 2, 2006 3:04 PM",
+}
+
+// languageTag loosely matches BCP 47 tags such as "en", "pt-BR" or "zh-Hant"
+var languageTag = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{1,8})*$`)
+
+// preferences are a user's settings with defaults filled in
+type preferences struct {
+	location    *time.Location
+	dateLayout  string // Empty keeps the layout each output uses by default
+	browseLimit int32
+	oldestFirst bool
+	language    string // Only declared by email digests, the CLI speaks English
+}
+
+// loadPreferences reads the user's settings. Missing or unusable values
+// fall back to the defaults
+func loadPreferences(ctx context.Context, s *state.State, userID uuid.UUID) preferences {
+	prefs := defaultPreferences()
+
+	settings, err := s.DB.GetUserSettings(ctx, userID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			slog.Warn("cannot get user settings", "user_id", userID, "error", err)
+		}
+		return prefs
+	}
+
+	if settings.Timezone.Valid {
+		if location, err := time.LoadLocation(settings.Timezone.String); err == nil {
+			prefs.location = location
+		}
+	}
+	if settings.DateFormat.Valid {
+		prefs.dateLayout = dateLayout(settings.DateFormat.String)
+	}
+	if settings.BrowseLimit.Valid {
+		prefs.browseLimit = settings.BrowseLimit.Int32
+	}
+	prefs.oldestFirst = settings.SortOrder.String == "oldest"
+	prefs.language = settings.Language.String
+	return prefs
+}
+
+// defaultPreferences are the preferences of users without settings
+func defaultPreferences() preferences {
+	return preferences{location: time.UTC, browseLimit: defaultBrowseLimit}
+}
+
+// currentPreferences reads the logged in user's settings, for commands
+// that run without a login. Nobody logged in gets the defaults
+func currentPreferences(ctx context.Context, s *state.State) preferences {
+	user, _, err := authenticate(ctx, s)
+	if err != nil {
+		return defaultPreferences()
+	}
+	return loadPreferences(ctx, s, user.ID)
+}
+
+// formatTime shows t in the user's timezone, using their date format
+// if they set one and layout otherwise
+func (p preferences) formatTime(t time.Time, layout string) string {
+	if p.dateLayout != "" {
+		layout = p.dateLayout
+	}
+	return t.In(p.location).Format(layout)
+}
+
+// dateLayout resolves a date_format value to a Go time layout
+func dateLayout(format string) string {
+	if layout, ok := dateFormats[format]; ok {
+		return layout
+	}
+	return format
+}
+
+func HandlerSettings(ctx context.Context, s *state.State, cmd Command, user database.User) error {
+	switch {
+	case len(cmd.Args) == 0 || (len(cmd.Args) == 1 && cmd.Args[0] == "show"):
+		return settingsShow(ctx, s, user)
+	case cmd.Args[0] == "set" && len(cmd.Args) == 3:
+		return settingsSet(ctx, s, user, cmd.Args[1], cmd.Args[2])
+	case cmd.Args[0] == "reset" && len(cmd.Args) <= 2:
+		key := ""
+		if len(cmd.Args) == 2 {
+			key = cmd.Args[1]
+		}
+		return settingsReset(ctx, s, user, key)
+	}
+	return cmd.usageError()
+}
+
+func settingsShow(ctx context.Context, s *state.State, user database.User) error {
+	settings, err := getUserSettings(ctx, s, user)
+	if err != nil {
+		return err
+	}
+
+	defaults := map[string]string{
+		"timezone":     "UTC",
+		"date_format":  "depends on the output",
+		"browse_limit": strconv.Itoa(defaultBrowseLimit),
+		"sort":         "newest",
+		"language":     "none, digests declare no language",
+	}
+	for _, key := range settingKeys {
+		value := settingValue(settings, key)
+		if !value.Valid {
+			value.String = "(default: " + defaults[key] + ")"
+		}
+		fmt.Printf("%-13s %s\n", key+":", value.String)
+	}
+	return nil
+}
+
+func settingsSet(ctx context.Context, s *state.State, user database.User, key, value string) error {
+	if err := validateSetting(key, value); err != nil {
+		return err
+	}
+
+	settings, err := getUserSettings(ctx, s, user)
+	if err != nil {
+		return err
+	}
+	if err := saveSetting(ctx, s, settings, key, sql.NullString{String: value, Valid: true}); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s set to %s\n", key, value)
+	return nil
+}
+
+func settingsReset(ctx context.Context, s *state.State, user database.User, key string) error {
+	if key == "" {
+		if err := s.DB.DeleteUserSettings(ctx, user.ID); err != nil {
+			return fmt.Errorf("cannot reset settings: %v", err)
+		}
+		fmt.Println("All settings reset to their defaults")
+		return nil
+	}
+
+	settings, err := getUserSettings(ctx, s, user)
+	if err != nil {
+		return err
+	}
+	if err := saveSetting(ctx, s, settings, key, sql.NullString{}); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s reset to its default\n", key)
+	return nil
+}
+
+// getUserSettings returns the stored settings, all unset if there are none
+func getUserSettings(ctx context.Context, s *state.State, user database.User) (database.UserSetting, error) {
+	settings, err := s.DB.GetUserSettings(ctx, user.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.UserSetting{UserID: user.ID}, nil
+	}
+	if err != nil {
+		return database.UserSetting{}, fmt.Errorf("cannot get settings: %v", err)
+	}
+	return settings, nil
+}
+
+// validateSetting checks a value before it is stored
+func validateSetting(key, value string) error {
+	switch key {
+	case "timezone":
+		if _, err := time.LoadLocation(value); err != nil || value == "" || value == "Local" {
+			return fmt.Errorf("unknown timezone '%s', use a name such as Europe/Berlin or America/New_York", value)
+		}
+	case "date_format":
+		if _, ok := dateFormats[value]; !ok && time.Now().Format(value) == value {
+			return fmt.Errorf("invalid date format '%s', use one of %s or a Go time layout", value, dateFormatNames())
+		}
+	case "browse_limit":
+		if limit, err := strconv.Atoi(value); err != nil || limit < 1 {
+			return fmt.Errorf("browse_limit must be a positive number")
+		}
+	case "sort":
+		if value != "newest" && value != "oldest" {
+			return fmt.Errorf("sort must be newest or oldest")
+		}
+	case "language":
+		if !languageTag.MatchString(value) {
+			return fmt.Errorf("invalid language '%s', use a tag such as en or pt-BR", value)
+		}
+	default:
+		return unknownSetting(key)
+	}
+	return nil
+}
+
+// settingValue returns one setting as text
+func settingValue(settings database.UserSetting, key string) sql.NullString {
+	switch key {
+	case "timezone":
+		return settings.Timezone
+	case "date_format":
+		return settings.DateFormat
+	case "browse_limit":
+		return sql.NullString{String: strconv.Itoa(int(settings.BrowseLimit.Int32)), Valid: settings.BrowseLimit.Valid}
+	case "sort":
+		return settings.SortOrder
+	case "language":
+		return settings.Language
+	}
+	return sql.NullString{}
+}
+
+// saveSetting stores one setting, leaving the others as they are.
+// A null value resets it
+func saveSetting(ctx context.Context, s *state.State, settings database.UserSetting, key string, value sql.NullString) error {
+	switch key {
+	case "timezone":
+		settings.Timezone = value
+	case "date_format":
+		settings.DateFormat = value
+	case "browse_limit":
+		limit, _ := strconv.Atoi(value.String)
+		settings.BrowseLimit = sql.NullInt32{Int32: int32(limit), Valid: value.Valid}
+	case "sort":
+		settings.SortOrder = value
+	case "language":
+		settings.Language = value
+	default:
+		return unknownSetting(key)
+	}
+
+	err := s.DB.UpsertUserSettings(ctx, database.UpsertUserSettingsParams{
+		UserID:      settings.UserID,
+		UpdatedAt:   time.Now().UTC(),
+		Timezone:    settings.Timezone,
+		DateFormat:  settings.DateFormat,
+		BrowseLimit: settings.BrowseLimit,
+		SortOrder:   settings.SortOrder,
+		Language:    settings.Language,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot save settings: %v", err)
+	}
+	return nil
+}
+
+func unknownSetting(key string) error {
+	if suggestion := closest(key, settingKeys); suggestion != "" {
+		return fmt.Errorf("unknown setting '%s', did you mean '%s'?", key, suggestion)
+	}
+	return fmt.Errorf("unknown setting '%s', settings are %v", key, settingKeys)
+}
+
+func dateFormatNames() []string {
+	names := make([]string, 0, len(dateFormats))
+	for name := range dateFormats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// completeSettings offers subcommands, then keys, then known values
+func completeSettings(ctx context.Context, s *state.State, args []string) []string {
+	switch {
+	case len(args) == 0:
+		return []string{"show", "set", "reset"}
+	case len(args) == 1 && (args[0] == "set" || args[0] == "reset"):
+		return settingKeys
+	case len(args) == 2 && args[0] == "set" && args[1] == "sort":
+		return []string{"newest", "oldest"}
+	case len(args) == 2 && args[0] == "set" && args[1] == "date_format":
+		return dateFormatNames()
+	}
+	return nil
+}
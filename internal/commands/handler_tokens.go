@@ -0,0 +1,165 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/twomotive/GoFlux/internal/auth"
+	"github.com/twomotive/GoFlux/internal/database"
+	"github.com/twomotive/GoFlux/internal/state"
+)
+
+// HandlerToken manages the current user's personal API tokens
+func HandlerToken(ctx context.Context, s *state.State, cmd Command, user database.User) error {
+	if len(cmd.Args) == 0 {
+		return cmd.usageError()
+	}
+
+	// A leaked token must not be able to mint or revoke other tokens
+	if s.Cfg.Token != "" {
+		return fmt.Errorf("API tokens cannot manage tokens, log in with your password instead")
+	}
+
+	switch cmd.Args[0] {
+	case "create":
+		return tokenCreate(ctx, s, cmd, user)
+	case "list":
+		return tokenList(ctx, s, user)
+	case "revoke":
+		return tokenRevoke(ctx, s, cmd.Args[1:], user)
+	default:
+		return cmd.usageError()
+	}
+}
+
+func tokenCreate(ctx context.Context, s *state.State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 2 {
+		return cmd.usageError()
+	}
+	name := cmd.Args[1]
+
+	lifetime, err := parseLifetime(cmd.Flag("expires"))
+	if err != nil {
+		return err
+	}
+
+	scopes, err := auth.ParseScopes(cmd.Flag("scopes"))
+	if err != nil {
+		return err
+	}
+
+	token, err := auth.NewAPIToken()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	var expiresAt sql.NullTime
+	if lifetime > 0 {
+		expiresAt = sql.NullTime{Time: now.Add(lifetime), Valid: true}
+	}
+
+	_, err = s.DB.CreateAPIToken(ctx, database.CreateAPITokenParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UserID:    user.ID,
+		Name:      name,
+		TokenHash: auth.HashToken(token),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		if isDuplicateError(err) {
+			return fmt.Errorf("you already have a token named '%s'", name)
+		}
+		return fmt.Errorf("cannot create token: %v", err)
+	}
+
+	fmt.Printf("Token '%s' created with scopes %s\n", name, scopes)
+	if expiresAt.Valid {
+		prefs := loadPreferences(ctx, s, user.ID)
+		fmt.Printf("Expires: %s\n", prefs.formatTime(expiresAt.Time, time.RFC3339))
+	}
+	fmt.Printf("Token: %s\n", token)
+	fmt.Println("Store it now, it cannot be shown again. Use it by setting GOFLUX_TOKEN.")
+	return nil
+}
+
+func tokenList(ctx context.Context, s *state.State, user database.User) error {
+	tokens, err := s.DB.GetAPITokensByUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("cannot get tokens from database: %v", err)
+	}
+
+	if len(tokens) == 0 {
+		fmt.Println("No API tokens.")
+		return nil
+	}
+
+	prefs := loadPreferences(ctx, s, user.ID)
+	now := time.Now()
+	for _, token := range tokens {
+		expires := "never"
+		if token.ExpiresAt.Valid {
+			expires = prefs.formatTime(token.ExpiresAt.Time, time.DateTime)
+			if token.ExpiresAt.Time.Before(now) {
+				expires += " (expired)"
+			}
+		}
+		lastUsed := "never"
+		if token.LastUsedAt.Valid {
+			lastUsed = prefs.formatTime(token.LastUsedAt.Time, time.DateTime)
+		}
+		fmt.Printf("* %s [%s]\n", token.Name, token.Scopes)
+		fmt.Printf("    created:   %s\n", prefs.formatTime(token.CreatedAt, time.DateTime))
+		fmt.Printf("    expires:   %s\n", expires)
+		fmt.Printf("    last used: %s\n", lastUsed)
+	}
+	return nil
+}
+
+func tokenRevoke(ctx context.Context, s *state.State, args []string, user database.User) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: token revoke <name>")
+	}
+
+	deleted, err := s.DB.DeleteAPIToken(ctx, database.DeleteAPITokenParams{
+		UserID: user.ID,
+		Name:   args[0],
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke token: %v", err)
+	}
+	if deleted == 0 {
+		return fmt.Errorf("token '%s' not found", args[0])
+	}
+
+	fmt.Printf("Token '%s' revoked\n", args[0])
+	return nil
+}
+
+// parseLifetime accepts Go durations, a number of days such as "90d",
+// or "never". Zero means the token does not expire
+func parseLifetime(value string) (time.Duration, error) {
+	if value == "never" {
+		return 0, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid expiry '%s'", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	lifetime, err := time.ParseDuration(value)
+	if err != nil || lifetime <= 0 {
+		return 0, fmt.Errorf("invalid expiry '%s'", value)
+	}
+	return lifetime, nil
+}
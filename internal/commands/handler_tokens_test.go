@@ -0,0 +1,45 @@
+package commands
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLifetime(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "never", want: 0},
+		{value: "90d", want: 90 * 24 * time.Hour},
+		{value: "1d", want: 24 * time.Hour},
+		{value: "12h", want: 12 * time.Hour},
+		{value: "1h30m", want: 90 * time.Minute},
+		{value: "0d", wantErr: true},
+		{value: "-1d", wantErr: true},
+		{value: "d", wantErr: true},
+		{value: "1.5d", wantErr: true},
+		{value: "0s", wantErr: true},
+		{value: "-1h", wantErr: true},
+		{value: "soon", wantErr: true},
+		{value: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseLifetime(tt.value)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseLifetime(%q) = %v, want an error", tt.value, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseLifetime(%q) returned error: %v", tt.value, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseLifetime(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
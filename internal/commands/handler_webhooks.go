@@ -0,0 +1,228 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/twomotive/GoFlux/internal/database"
+	"github.com/twomotive/GoFlux/internal/state"
+	"github.com/twomotive/GoFlux/internal/webhooks"
+)
+
+// HandlerWebhook manages the current user's outgoing webhooks
+func HandlerWebhook(ctx context.Context, s *state.State, cmd Command, user database.User) error {
+	if len(cmd.Args) == 0 {
+		return cmd.usageError()
+	}
+
+	switch cmd.Args[0] {
+	case "add":
+		return webhookAdd(ctx, s, cmd, user)
+	case "list":
+		return webhookList(ctx, s, user)
+	case "remove":
+		return webhookRemove(ctx, s, cmd.Args[1:], user)
+	case "log":
+		return webhookLog(ctx, s, cmd.Args[1:], user)
+	default:
+		return cmd.usageError()
+	}
+}
+
+func webhookAdd(ctx context.Context, s *state.State, cmd Command, user database.User) error {
+	if len(cmd.Args) != 2 {
+		return cmd.usageError()
+	}
+	url, feedURL, keyword := cmd.Args[1], cmd.Flag("feed"), cmd.Flag("match")
+
+	var feedID uuid.NullUUID
+	if feedURL != "" {
+		feed, err := feedByURL(ctx, s, feedURL)
+		if err != nil {
+			return fmt.Errorf("cannot get feed from database: %v", err)
+		}
+		feedID = uuid.NullUUID{UUID: feed.ID, Valid: true}
+	}
+
+	secret, err := webhooks.NewSecret()
+	if err != nil {
+		return err
+	}
+
+	webhook, err := s.DB.CreateWebhook(ctx, database.CreateWebhookParams{
+		ID:           uuid.New(),
+		CreatedAt:    time.Now().UTC(),
+		UpdatedAt:    time.Now().UTC(),
+		UserID:       user.ID,
+		Url:          url,
+		Secret:       secret,
+		FeedID:       feedID,
+		MatchKeyword: sql.NullString{String: keyword, Valid: keyword != ""},
+	})
+	if err != nil {
+		return fmt.Errorf("cannot create webhook: %v", err)
+	}
+
+	fmt.Printf("Webhook added: %s\n", webhook.ID)
+	fmt.Printf("Signing secret: %s\n", webhook.Secret)
+	fmt.Println("Deliveries are signed with HMAC-SHA256 in the X-GoFlux-Signature header.")
+	fmt.Println("Only posts from feeds you follow are delivered.")
+	return nil
+}
+
+func webhookList(ctx context.Context, s *state.State, user database.User) error {
+	hooks, err := s.DB.GetWebhooksByUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("cannot get webhooks from database: %v", err)
+	}
+
+	if len(hooks) == 0 {
+		fmt.Println("No webhooks registered.")
+		return nil
+	}
+
+	for _, hook := range hooks {
+		fmt.Printf("* %s %s\n", hook.ID, hook.Url)
+		if hook.FeedUrl.Valid {
+			fmt.Printf("    feed:  %s\n", hook.FeedUrl.String)
+		}
+		if hook.MatchKeyword.Valid {
+			fmt.Printf("    match: %s\n", hook.MatchKeyword.String)
+		}
+	}
+	return nil
+}
+
+func webhookRemove(ctx context.Context, s *state.State, args []string, user database.User) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: webhook remove <id>")
+	}
+
+	id, err := uuid.Parse(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid webhook id: %v", err)
+	}
+
+	deleted, err := s.DB.DeleteWebhook(ctx, database.DeleteWebhookParams{
+		ID:     id,
+		UserID: user.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove webhook: %v", err)
+	}
+	if deleted == 0 {
+		return fmt.Errorf("webhook '%s' not found", id)
+	}
+
+	fmt.Printf("Webhook '%s' removed\n", id)
+	return nil
+}
+
+func webhookLog(ctx context.Context, s *state.State, args []string, user database.User) error {
+	var limit int32 = 20
+	if len(args) > 0 {
+		parsedLimit, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid limit: %v", err)
+		}
+		limit = int32(parsedLimit)
+	}
+
+	deliveries, err := s.DB.GetWebhookDeliveriesByUser(ctx, database.GetWebhookDeliveriesByUserParams{
+		UserID: user.ID,
+		Limit:  limit,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot get webhook deliveries from database: %v", err)
+	}
+
+	if len(deliveries) == 0 {
+		fmt.Println("No deliveries yet.")
+		return nil
+	}
+
+	prefs := loadPreferences(ctx, s, user.ID)
+	for _, d := range deliveries {
+		status := "ok"
+		if !d.Succeeded {
+			status = "failed"
+		}
+		fmt.Printf("%s [%s] %s -> %s (attempts: %d", prefs.formatTime(d.CreatedAt, time.RFC3339), status, d.PostTitle, d.WebhookUrl, d.Attempts)
+		if d.StatusCode.Valid {
+			fmt.Printf(", status: %d", d.StatusCode.Int32)
+		}
+		fmt.Println(")")
+		if d.Error.Valid {
+			fmt.Printf("    error: %s\n", d.Error.String)
+		}
+	}
+	return nil
+}
+
+// notifyWebhooks queues a new post for the webhooks matching it. The
+// deliveries run in the background, retries included
+func notifyWebhooks(s *state.State, bg *background, hooks []database.Webhook, feed database.Feed, post database.Post) {
+	payload := webhooks.Payload{
+		Event:  webhooks.EventNewPost,
+		SentAt: time.Now().UTC(),
+		Feed: webhooks.PayloadFeed{
+			Name: feed.Name,
+			URL:  feed.Url,
+		},
+		Post: webhooks.PayloadPost{
+			ID:          post.ID.String(),
+			Title:       post.Title,
+			URL:         post.Url,
+			Description: post.Description.String,
+			Author:      post.Author.String,
+		},
+	}
+	if post.PublishedAt.Valid {
+		payload.Post.PublishedAt = &post.PublishedAt.Time
+	}
+
+	for _, hook := range hooks {
+		if !webhooks.Matches(hook.MatchKeyword.String, post.Title, post.Description.String) {
+			continue
+		}
+		bg.webhooks.Go(func(ctx context.Context) {
+			deliverWebhook(ctx, s, hook, post.ID, payload)
+		})
+	}
+}
+
+// deliverWebhook sends a payload to one webhook and records the outcome
+func deliverWebhook(ctx context.Context, s *state.State, hook database.Webhook, postID uuid.UUID, payload webhooks.Payload) {
+	result := webhooks.Deliver(ctx, hook.Url, hook.Secret, payload)
+	if result.Err != nil {
+		slog.Warn("webhook delivery failed", "webhook_id", hook.ID, "url", redactURL(hook.Url), "post_id", postID, "attempts", result.Attempts, "error", result.Err)
+	}
+
+	// Record the delivery even when shutting down cut it short
+	err := s.DB.CreateWebhookDelivery(context.WithoutCancel(ctx), database.CreateWebhookDeliveryParams{
+		ID:         uuid.New(),
+		CreatedAt:  time.Now().UTC(),
+		WebhookID:  hook.ID,
+		PostID:     postID,
+		Attempts:   int32(result.Attempts),
+		StatusCode: sql.NullInt32{Int32: int32(result.StatusCode), Valid: result.StatusCode != 0},
+		Error:      errorString(result.Err),
+		Succeeded:  result.Err == nil,
+	})
+	if err != nil {
+		slog.Error("cannot record webhook delivery", "webhook_id", hook.ID, "post_id", postID, "error", err)
+	}
+}
+
+// errorString converts an error to a nullable database string
+func errorString(err error) sql.NullString {
+	if err == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: err.Error(), Valid: true}
+}
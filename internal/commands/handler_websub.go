@@ -0,0 +1,337 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/twomotive/GoFlux/internal/database"
+	"github.com/twomotive/GoFlux/internal/rssfeeds"
+	"github.com/twomotive/GoFlux/internal/state"
+	"github.com/twomotive/GoFlux/internal/webhooks"
+	"github.com/twomotive/GoFlux/internal/websub"
+)
+
+const (
+	// websubRenewInterval is how often leases are checked and renewed
+	websubRenewInterval = time.Hour
+	// websubRenewBefore renews a lease when it expires within this window
+	websubRenewBefore = 24 * time.Hour
+	// websubRediscoverInterval is how long a feed found without a hub is
+	// left alone before it is fetched again to look for one
+	websubRediscoverInterval = 24 * time.Hour
+	// maxPushBodySize caps the size of content accepted from a hub
+	maxPushBodySize = 10 << 20
+)
+
+// HandlerWebSub runs the WebSub callback endpoint or lists subscriptions
+func HandlerWebSub(ctx context.Context, s *state.State, cmd Command) error {
+	if len(cmd.Args) == 0 {
+		return cmd.usageError()
+	}
+
+	switch cmd.Args[0] {
+	case "serve":
+		if len(cmd.Args) != 3 {
+			return fmt.Errorf("usage: websub serve <listen_addr> <callback_base_url>")
+		}
+		return websubServe(ctx, s, cmd.Args[1], strings.TrimRight(cmd.Args[2], "/"))
+	case "list":
+		return websubList(ctx, s)
+	default:
+		return cmd.usageError()
+	}
+}
+
+func websubList(ctx context.Context, s *state.State) error {
+	subs, err := s.DB.GetWebSubSubscriptions(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot get websub subscriptions: %v", err)
+	}
+
+	if len(subs) == 0 {
+		fmt.Println("No WebSub subscriptions.")
+		return nil
+	}
+
+	prefs := currentPreferences(ctx, s)
+	for _, sub := range subs {
+		status := "pending"
+		if sub.LeaseExpiresAt.Valid {
+			status = "active until " + prefs.formatTime(sub.LeaseExpiresAt.Time, time.RFC1123)
+		}
+		fmt.Printf("* %s (%s)\n", sub.FeedName, status)
+		fmt.Printf("    hub:   %s\n", sub.HubUrl)
+		fmt.Printf("    topic: %s\n", sub.TopicUrl)
+	}
+	return nil
+}
+
+// websubServe listens for hub callbacks and keeps subscriptions renewed
+func websubServe(ctx context.Context, s *state.State, addr, callbackBase string) error {
+	bg := newBackground()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /websub/{feedID}", func(w http.ResponseWriter, r *http.Request) {
+		handleWebSubVerify(s, w, r)
+	})
+	mux.HandleFunc("POST /websub/{feedID}", func(w http.ResponseWriter, r *http.Request) {
+		handleWebSubPush(s, bg, w, r)
+	})
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+
+	slog.Info("listening for websub callbacks", "addr", addr)
+
+	ticker := time.NewTicker(websubRenewInterval)
+	defer ticker.Stop()
+
+	// Subscribe immediately and then renew on ticker
+	noHubUntil := make(map[uuid.UUID]time.Time)
+	for {
+		subscribeWebSubFeeds(ctx, s, callbackBase, noHubUntil)
+
+		select {
+		case err := <-serverErr:
+			return fmt.Errorf("websub server stopped: %v", err)
+		case <-ctx.Done():
+			return shutdownWebSubServer(s, server, bg)
+		case <-ticker.C:
+		}
+	}
+}
+
+// shutdownWebSubServer stops accepting callbacks and waits for requests
+// in flight, up to the configured drain period
+func shutdownWebSubServer(s *state.State, server *http.Server, bg *background) error {
+	drain, err := s.Cfg.Crawl.DrainPeriod()
+	if err != nil {
+		return err
+	}
+
+	slog.Info("shutting down, waiting for pending websub callbacks", "drain_timeout", drain)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), drain)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("websub server did not shut down cleanly: %v", err)
+	}
+	// Pushes received before shutdown may still have work in the background
+	deadline, _ := shutdownCtx.Deadline()
+	bg.Drain(time.Until(deadline))
+	return nil
+}
+
+// subscribeWebSubFeeds subscribes to every feed that advertises a hub
+// and whose lease is missing or about to expire. Feeds seen without a
+// hub are recorded in noHubUntil and skipped until it passes
+func subscribeWebSubFeeds(ctx context.Context, s *state.State, callbackBase string, noHubUntil map[uuid.UUID]time.Time) {
+	feeds, err := s.DB.GetFeeds(ctx)
+	if err != nil {
+		slog.Error("cannot get feeds for websub", "error", err)
+		return
+	}
+
+	for _, feed := range feeds {
+		sub, err := s.DB.GetWebSubSubscriptionByFeed(ctx, feed.ID)
+		if err != nil && err != sql.ErrNoRows {
+			slog.Error("cannot get websub subscription", "feed_id", feed.ID, "error", err)
+			continue
+		}
+		subscribed := err == nil
+		if subscribed && sub.LeaseExpiresAt.Valid && time.Until(sub.LeaseExpiresAt.Time) > websubRenewBefore {
+			continue
+		}
+		if time.Now().Before(noHubUntil[feed.ID]) {
+			continue
+		}
+
+		rssFeed, err := fetchFeed(ctx, s, feed)
+		if err != nil {
+			slog.Warn("cannot fetch feed for websub discovery", "feed_id", feed.ID, "url", redactURL(feed.Url), "error", err)
+			continue
+		}
+
+		hubURL := rssFeed.HubURL()
+		if hubURL == "" {
+			noHubUntil[feed.ID] = time.Now().Add(websubRediscoverInterval)
+			continue
+		}
+		delete(noHubUntil, feed.ID)
+		topicURL := rssFeed.SelfURL()
+		if topicURL == "" {
+			topicURL = feed.Url
+		}
+
+		// Renewals keep the secret, so pushes signed with it stay valid
+		// whether or not the hub verifies the new request
+		secret := sub.Secret
+		if !subscribed {
+			secret, err = webhooks.NewSecret()
+			if err != nil {
+				slog.Error("cannot generate websub secret", "error", err)
+				continue
+			}
+		}
+
+		_, err = s.DB.UpsertWebSubSubscription(ctx, database.UpsertWebSubSubscriptionParams{
+			FeedID:    feed.ID,
+			CreatedAt: time.Now().UTC(),
+			UpdatedAt: time.Now().UTC(),
+			HubUrl:    hubURL,
+			TopicUrl:  topicURL,
+			Secret:    secret,
+		})
+		if err != nil {
+			slog.Error("cannot save websub subscription", "feed_id", feed.ID, "error", err)
+			continue
+		}
+
+		callbackURL := callbackBase + "/websub/" + feed.ID.String()
+		if err := websub.Subscribe(ctx, hubURL, topicURL, callbackURL, secret, websub.DefaultLeaseSeconds); err != nil {
+			slog.Warn("websub subscription failed", "feed_id", feed.ID, "hub", hubURL, "error", err)
+			continue
+		}
+
+		slog.Info("requested websub subscription", "feed_id", feed.ID, "hub", hubURL, "topic", topicURL)
+	}
+}
+
+// handleWebSubVerify answers the hub's intent verification request
+func handleWebSubVerify(s *state.State, w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	sub, ok := lookupWebSubSubscription(s, w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	mode := query.Get("hub.mode")
+	topic := query.Get("hub.topic")
+	challenge := query.Get("hub.challenge")
+
+	if mode == "denied" {
+		slog.Warn("hub denied websub subscription", "feed_id", sub.FeedID, "topic", sub.TopicUrl, "reason", query.Get("hub.reason"))
+		if err := s.DB.DeleteWebSubSubscription(ctx, sub.FeedID); err != nil {
+			slog.Error("cannot remove denied websub subscription", "feed_id", sub.FeedID, "error", err)
+		}
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if topic != sub.TopicUrl || challenge == "" {
+		http.Error(w, "unknown topic", http.StatusNotFound)
+		return
+	}
+
+	switch mode {
+	case "subscribe":
+		leaseSeconds, err := strconv.Atoi(query.Get("hub.lease_seconds"))
+		if err != nil || leaseSeconds <= 0 {
+			leaseSeconds = websub.DefaultLeaseSeconds
+		}
+		now := time.Now().UTC()
+		err = s.DB.MarkWebSubVerified(ctx, database.MarkWebSubVerifiedParams{
+			FeedID:         sub.FeedID,
+			VerifiedAt:     sql.NullTime{Time: now, Valid: true},
+			LeaseExpiresAt: sql.NullTime{Time: now.Add(time.Duration(leaseSeconds) * time.Second), Valid: true},
+		})
+		if err != nil {
+			http.Error(w, "cannot record subscription", http.StatusInternalServerError)
+			return
+		}
+		slog.Info("websub subscription verified", "feed_id", sub.FeedID, "topic", sub.TopicUrl, "lease_seconds", leaseSeconds)
+	case "unsubscribe":
+		if err := s.DB.DeleteWebSubSubscription(ctx, sub.FeedID); err != nil {
+			http.Error(w, "cannot remove subscription", http.StatusInternalServerError)
+			return
+		}
+		slog.Info("websub subscription removed", "feed_id", sub.FeedID, "topic", sub.TopicUrl)
+	default:
+		http.Error(w, "unknown mode", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	io.WriteString(w, challenge)
+}
+
+// handleWebSubPush ingests content distributed by the hub
+func handleWebSubPush(s *state.State, bg *background, w http.ResponseWriter, r *http.Request) {
+	sub, ok := lookupWebSubSubscription(s, w, r)
+	if !ok {
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxPushBodySize))
+	if err != nil {
+		http.Error(w, "cannot read body", http.StatusBadRequest)
+		return
+	}
+
+	// The spec requires a 2xx even for bad signatures, the content is just ignored
+	w.WriteHeader(http.StatusAccepted)
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+
+	if !websub.VerifySignature(sub.Secret, r.Header.Get("X-Hub-Signature"), body) {
+		slog.Warn("ignoring websub push with invalid signature", "feed_id", sub.FeedID, "topic", sub.TopicUrl)
+		return
+	}
+
+	rssFeed, err := rssfeeds.ParseFeed(body)
+	if err != nil {
+		slog.Warn("cannot parse websub push", "feed_id", sub.FeedID, "topic", sub.TopicUrl, "error", err)
+		return
+	}
+
+	// The response is already sent, so don't tie ingestion to the request context
+	ctx := context.Background()
+
+	feed, err := s.DB.GetFeedByID(ctx, sub.FeedID)
+	if err != nil {
+		slog.Error("cannot get feed for websub push", "feed_id", sub.FeedID, "error", err)
+		return
+	}
+
+	slog.Info("received websub push", "feed_id", feed.ID, "url", redactURL(feed.Url), "items", len(rssFeed.Channel.Item))
+	if err := saveFeedMetadata(ctx, s, feed.ID, rssFeed); err != nil {
+		slog.Error("cannot save feed metadata", "feed_id", feed.ID, "error", err)
+	}
+	savePosts(ctx, s, bg, feed, rssFeed.Channel.Item)
+}
+
+// lookupWebSubSubscription resolves the subscription named in the callback path,
+// writing a 404 when it is unknown
+func lookupWebSubSubscription(s *state.State, w http.ResponseWriter, r *http.Request) (database.WebsubSubscription, bool) {
+	feedID, err := uuid.Parse(r.PathValue("feedID"))
+	if err != nil {
+		http.Error(w, "unknown subscription", http.StatusNotFound)
+		return database.WebsubSubscription{}, false
+	}
+
+	sub, err := s.DB.GetWebSubSubscriptionByFeed(r.Context(), feedID)
+	if err != nil {
+		http.Error(w, "unknown subscription", http.StatusNotFound)
+		return database.WebsubSubscription{}, false
+	}
+
+	return sub, true
+}
@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/twomotive/GoFlux/internal/state"
+)
+
+// GlobalUsage lists the flags accepted before the command name
+const GlobalUsage = "goflux [--config path] [--profile name] [--log-level level] [--log-format text|json] <command> [args...]"
+
+// handlerHelp lists every command, or describes one in detail
+func (c *Commands) handlerHelp(ctx context.Context, s *state.State, cmd Command) error {
+	if len(cmd.Args) > 1 {
+		return cmd.usageError()
+	}
+	if len(cmd.Args) == 1 {
+		spec, ok := c.Lookup(cmd.Args[0])
+		if !ok {
+			return c.UnknownCommand(cmd.Args[0])
+		}
+		printCommandHelp(spec)
+		return nil
+	}
+
+	fmt.Printf("Usage: %s\n\nCommands:\n", GlobalUsage)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, spec := range c.Specs() {
+		if spec.Hidden {
+			continue
+		}
+		fmt.Fprintf(w, "  %s\t%s\n", spec.Name, spec.Summary)
+	}
+	w.Flush()
+	fmt.Println("\nRun 'help <command>' or '<command> --help' for details on one command.")
+	return nil
+}
+
+// printCommandHelp describes one command with its flags and examples
+func printCommandHelp(spec *Spec) {
+	fmt.Printf("%s - %s\n\nUsage:\n", spec.Name, spec.Summary)
+	if len(spec.Usage) == 0 {
+		fmt.Printf("  goflux %s\n", spec.usageLine())
+	}
+	for _, form := range spec.Usage {
+		fmt.Printf("  goflux %s\n", strings.TrimSpace(spec.Name+" "+form))
+	}
+
+	if len(spec.Aliases) > 0 {
+		fmt.Printf("\nAliases: %s\n", strings.Join(spec.Aliases, ", "))
+	}
+
+	if len(spec.Flags) > 0 {
+		fmt.Println("\nFlags:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, f := range spec.Flags {
+			name := "    --" + f.Name
+			if f.Short != "" {
+				name = fmt.Sprintf("-%s, --%s", f.Short, f.Name)
+			}
+			if f.Value != "" {
+				name += " <" + f.Value + ">"
+			}
+			usage := f.Usage
+			if f.Default != "" {
+				usage += fmt.Sprintf(" (default %s)", f.Default)
+			}
+			fmt.Fprintf(w, "  %s\t%s\n", name, usage)
+		}
+		w.Flush()
+	}
+
+	switch {
+	case spec.Admin:
+		fmt.Println("\nRequires an admin account.")
+	case spec.RequiresLogin():
+		fmt.Println("\nRequires a logged in user or an API token.")
+	}
+
+	if len(spec.Examples) > 0 {
+		fmt.Println("\nExamples:")
+		for _, example := range spec.Examples {
+			fmt.Printf("  goflux %s\n", example)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/twomotive/GoFlux/internal/metrics"
+	"github.com/twomotive/GoFlux/internal/rssfeeds"
+	"github.com/twomotive/GoFlux/internal/state"
+)
+
+// metricsQueryTimeout bounds the database query made for each scrape
+const metricsQueryTimeout = 5 * time.Second
+
+// aggMetrics are recorded by agg and by WebSub pushes. They are only
+// exposed when agg runs with --metrics-addr
+var aggMetrics = newAggregatorMetrics(metrics.NewRegistry())
+
+type aggregatorMetrics struct {
+	registry          *metrics.Registry
+	fetches           *metrics.Counter
+	fetchDuration     *metrics.Histogram
+	postsInserted     *metrics.Counter
+	duplicatesSkipped *metrics.Counter
+	postsUpdated      *metrics.Counter
+	dateParseFailures *metrics.Counter
+}
+
+func newAggregatorMetrics(registry *metrics.Registry) *aggregatorMetrics {
+	return &aggregatorMetrics{
+		registry: registry,
+		fetches: registry.NewCounter("goflux_feed_fetches_total",
+			"Feed fetches by outcome and HTTP status.", "outcome", "status"),
+		fetchDuration: registry.NewHistogram("goflux_feed_fetch_duration_seconds",
+			"Time taken to fetch and parse a feed.", metrics.DefaultBuckets, "outcome"),
+		postsInserted: registry.NewCounter("goflux_posts_inserted_total",
+			"Posts saved from fetched or pushed feeds."),
+		duplicatesSkipped: registry.NewCounter("goflux_posts_duplicates_skipped_total",
+			"Feed items skipped because the post already exists."),
+		postsUpdated: registry.NewCounter("goflux_posts_updated_total",
+			"Posts whose title or description changed in their feed."),
+		dateParseFailures: registry.NewCounter("goflux_date_parse_failures_total",
+			"Item publication dates that could not be parsed."),
+	}
+}
+
+// recordFetch counts a finished fetch. The status is empty when no
+// HTTP response was received
+func (m *aggregatorMetrics) recordFetch(start time.Time, err error) {
+	outcome, status := "success", strconv.Itoa(http.StatusOK)
+	var statusErr *rssfeeds.StatusError
+	switch {
+	case err == nil:
+	case errors.As(err, &statusErr):
+		outcome, status = "http_error", strconv.Itoa(statusErr.StatusCode)
+	case errors.Is(err, context.DeadlineExceeded):
+		outcome, status = "timeout", ""
+	default:
+		outcome, status = "error", ""
+	}
+
+	m.fetches.Inc(outcome, status)
+	m.fetchDuration.ObserveDuration(start, outcome)
+}
+
+// serveMetrics exposes aggMetrics on addr until ctx is cancelled. Queue
+// lag is read from the database on every scrape, so it keeps growing
+// even when the agg loop itself is stuck
+func serveMetrics(ctx context.Context, s *state.State, addr string) error {
+	aggMetrics.registry.NewGaugeFunc("goflux_feed_queue_lag_seconds",
+		"Time since each feed was last fetched, or since it was added if never fetched.",
+		[]string{"feed_id", "url"}, func() []metrics.Sample {
+			return feedQueueLag(ctx, s)
+		})
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", aggMetrics.registry.Handler())
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// Listen before returning, so a bad address fails agg instead of
+	// leaving it running without metrics
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("cannot serve metrics: %v", err)
+	}
+
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("metrics server stopped", "addr", addr, "error", err)
+		}
+	}()
+	context.AfterFunc(ctx, func() {
+		server.Close()
+	})
+
+	slog.Info("serving metrics", "addr", addr, "path", "/metrics")
+	return nil
+}
+
+func feedQueueLag(ctx context.Context, s *state.State) []metrics.Sample {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), metricsQueryTimeout)
+	defer cancel()
+
+	feeds, err := s.DB.GetFeeds(ctx)
+	if err != nil {
+		slog.Error("cannot read feeds for metrics", "error", err)
+		return nil
+	}
+
+	now := time.Now()
+	samples := make([]metrics.Sample, 0, len(feeds))
+	for _, feed := range feeds {
+		since := feed.CreatedAt
+		if feed.LastFetchedAt.Valid {
+			since = feed.LastFetchedAt.Time
+		}
+		samples = append(samples, metrics.Sample{
+			Labels: []string{feed.ID.String(), redactURL(feed.Url)},
+			Value:  now.Sub(since).Seconds(),
+		})
+	}
+	return samples
+}
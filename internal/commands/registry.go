@@ -0,0 +1,256 @@
+package commands
+
+// yesFlag skips the confirmation prompt of destructive commands
+var yesFlag = Flag{Name: "yes", Short: "y", Usage: "do not ask for confirmation"}
+
+// categoryFlag limits listed posts to one category
+var categoryFlag = Flag{Name: "category", Value: "name", Usage: "only show posts in this category", Complete: completeCategories}
+
+// authorFlag limits listed posts to bylines containing a name
+var authorFlag = Flag{Name: "author", Value: "name", Usage: "only show posts whose byline contains this name"}
+
+// NewRegistry registers every command of the CLI
+func NewRegistry() *Commands {
+	c := NewCommands()
+
+	c.Register(Spec{
+		Name:     "help",
+		Summary:  "Show the available commands or help for one command",
+		Usage:    []string{"[command]"},
+		NoDB:     true,
+		Handler:  c.handlerHelp,
+		Complete: c.completeCommandNames,
+	})
+	c.Register(Spec{
+		Name:     "completion",
+		Summary:  "Print a shell completion script",
+		Usage:    []string{"bash|zsh|fish"},
+		Examples: []string{"completion bash > /etc/bash_completion.d/goflux", "completion fish | source"},
+		NoDB:     true,
+		Handler:  handlerCompletion,
+	})
+	c.Register(Spec{
+		Name:        "shell",
+		Summary:     "Run commands interactively over one connection and session",
+		Interactive: true,
+		Handler:     c.handlerShell,
+	})
+	c.Register(Spec{
+		Name:       "__complete",
+		Summary:    "Print completion candidates for a partial command line",
+		Usage:      []string{"[words...] <current>"},
+		Hidden:     true,
+		OptionalDB: true,
+		RawArgs:    true,
+		Handler:    c.handlerComplete,
+	})
+	c.Register(Spec{
+		Name:     "config",
+		Summary:  "Show and change configuration settings",
+		Usage:    []string{"get [key]", "set <key> <value>", "init", "path"},
+		Examples: []string{"config set db_url sqlite:///var/lib/goflux.db", "--profile work config get"},
+		NoDB:     true,
+		Handler:  HandlerConfig,
+	})
+	c.Register(Spec{
+		Name:     "migrate",
+		Summary:  "Apply, roll back or list schema migrations",
+		Usage:    []string{"up|status", "down|redo [--yes]"},
+		Flags:    []Flag{yesFlag},
+		Examples: []string{"migrate up", "migrate down --yes"},
+		Handler:  HandlerMigrate,
+	})
+
+	c.Register(Spec{
+		Name:     "register",
+		Aliases:  []string{"signup"},
+		Summary:  "Create an account and log in",
+		Usage:    []string{"<name>"},
+		Examples: []string{"register alice"},
+		Handler:  HandlerRegister,
+	})
+	c.Register(Spec{
+		Name:     "login",
+		Summary:  "Log in as an existing user",
+		Usage:    []string{"<name>"},
+		Handler:  HandlerLogin,
+		Complete: completeUserNames,
+	})
+	c.Register(Spec{
+		Name:    "logout",
+		Summary: "End the current session",
+		Handler: HandlerLogout,
+	})
+	c.Register(Spec{
+		Name:        "passwd",
+		Summary:     "Change your password and log out other sessions, or set another user's as an admin",
+		Usage:       []string{"[--user <name>]"},
+		Flags:       []Flag{{Name: "user", Value: "name", Usage: "set this user's password instead, admins only", Complete: completeUserNames}},
+		Examples:    []string{"passwd --user alice"},
+		UserHandler: HandlerPasswd,
+	})
+	c.Register(Spec{
+		Name:    "token",
+		Summary: "Manage personal API tokens",
+		Usage:   []string{"create <name> [--scopes read,write] [--expires 90d|never]", "list", "revoke <name>"},
+		Flags: []Flag{
+			{Name: "scopes", Value: "list", Default: "read", Usage: "comma separated scopes for create: read, write"},
+			{Name: "expires", Value: "lifetime", Default: "90d", Usage: "lifetime for create, e.g. 30d, 12h or never"},
+		},
+		Examples:    []string{"token create ci --scopes read,write --expires 30d", "token revoke ci"},
+		UserHandler: HandlerToken,
+	})
+	c.Register(Spec{
+		Name:     "users",
+		Summary:  "List users, or delete them and change roles as an admin",
+		Usage:    []string{"[list]", "delete <name> [--yes]", "role <name> admin|member"},
+		Flags:    []Flag{yesFlag},
+		Handler:  HandlerGetUsers,
+		Complete: completeUsers,
+	})
+	c.Register(Spec{
+		Name:    "reset",
+		Summary: "Delete all users, feeds or posts",
+		Flags: []Flag{
+			{Name: "posts-only", Usage: "delete posts but keep users and feeds"},
+			{Name: "feeds-only", Usage: "delete feeds with their posts but keep users"},
+			yesFlag,
+		},
+		Admin:       true,
+		UserHandler: HandlerReset,
+	})
+
+	c.Register(Spec{
+		Name:     "agg",
+		Summary:  "Fetch feeds continuously and send due digests",
+		Usage:    []string{"<time_between_reqs> [--metrics-addr <listen_addr>]"},
+		Flags:    []Flag{{Name: "metrics-addr", Value: "listen_addr", Usage: "serve Prometheus metrics on this address"}},
+		Examples: []string{"agg 1m", "--log-format json agg 30s --metrics-addr :9100"},
+		Handler:  HandlerAgg,
+	})
+	c.Register(Spec{
+		Name:        "addfeed",
+		Aliases:     []string{"add"},
+		Summary:     "Add a feed and follow it, named after its title unless a name is given",
+		Usage:       []string{"[name] <url>"},
+		Examples:    []string{"addfeed https://go.dev/blog/feed.atom", "addfeed \"Go Blog\" https://go.dev/blog/feed.atom"},
+		UserHandler: HandlerAddFeed,
+	})
+	c.Register(Spec{
+		Name:        "removefeed",
+		Aliases:     []string{"rmfeed"},
+		Summary:     "Delete a feed for everyone following it",
+		Usage:       []string{"<url> [--yes]"},
+		Flags:       []Flag{yesFlag},
+		Admin:       true,
+		UserHandler: HandlerRemoveFeed,
+		Complete:    completeFeedURLs,
+	})
+	c.Register(Spec{
+		Name:    "feeds",
+		Summary: "List all feeds",
+		Handler: HandlerGetFeeds,
+	})
+	c.Register(Spec{
+		Name:        "follow",
+		Summary:     "Follow a feed",
+		Usage:       []string{"<url>"},
+		UserHandler: HandlerFollow,
+		Complete:    completeFeedURLs,
+	})
+	c.Register(Spec{
+		Name:        "following",
+		Aliases:     []string{"subs"},
+		Summary:     "List the feeds you follow",
+		Scope:       readScope,
+		UserHandler: HandlerFollowing,
+	})
+	c.Register(Spec{
+		Name:        "unfollow",
+		Summary:     "Stop following a feed",
+		Usage:       []string{"<url>"},
+		UserHandler: HandlerUnfollow,
+		Complete:    completeFollowedURLs,
+	})
+	c.Register(Spec{
+		Name:        "browse",
+		Aliases:     []string{"posts"},
+		Summary:     "Show the latest posts from feeds you follow",
+		Usage:       []string{"[limit] [--category <name>] [--author <name>]"},
+		Flags:       []Flag{categoryFlag, authorFlag},
+		Examples:    []string{"browse 10", "browse 5 --category golang", "browse --author \"Rob Pike\""},
+		Scope:       readScope,
+		UserHandler: HandlerBrowse,
+	})
+	c.Register(Spec{
+		Name:        "search",
+		Summary:     "Find posts from feeds you follow by title or description",
+		Usage:       []string{"<text> [limit] [--category <name>] [--author <name>]"},
+		Flags:       []Flag{categoryFlag, authorFlag},
+		Examples:    []string{"search generics 10", "search release --category golang"},
+		Scope:       readScope,
+		UserHandler: HandlerSearch,
+	})
+	c.Register(Spec{
+		Name:        "diff",
+		Summary:     "Show how a post changed when its feed edited it",
+		Usage:       []string{"<post_url|post_id>"},
+		Examples:    []string{"diff https://example.com/advisories/2026-01"},
+		Scope:       readScope,
+		UserHandler: HandlerDiff,
+	})
+
+	c.Register(Spec{
+		Name:        "settings",
+		Summary:     "Show and change your timezone, date format, browsing defaults and digest language",
+		Usage:       []string{"[show]", "set timezone|date_format|browse_limit|sort|language <value>", "reset [key]"},
+		Examples:    []string{"settings set timezone America/New_York", "settings set date_format datetime", "settings set sort oldest", "settings set language de"},
+		Scope:       readSubcommands("", "show"),
+		UserHandler: HandlerSettings,
+		Complete:    completeSettings,
+	})
+	c.Register(Spec{
+		Name:    "webhook",
+		Summary: "Push new posts to HTTP endpoints",
+		Usage:   []string{"add <url> [--feed <url>] [--match <keyword>]", "list", "remove <id>", "log [limit]"},
+		Flags: []Flag{
+			{Name: "feed", Value: "url", Usage: "only deliver posts from this feed", Complete: completeFeedURLs},
+			{Name: "match", Value: "keyword", Usage: "only deliver posts mentioning this keyword"},
+		},
+		Examples:    []string{"webhook add https://example.com/hook --match golang"},
+		Scope:       readSubcommands("list", "log"),
+		UserHandler: HandlerWebhook,
+	})
+	c.Register(Spec{
+		Name:        "digest",
+		Summary:     "Manage your email digest",
+		Usage:       []string{"subscribe <email> [daily|weekly]", "unsubscribe", "status", "preview", "send"},
+		Examples:    []string{"digest subscribe me@example.com weekly"},
+		Scope:       readSubcommands("status", "preview"),
+		UserHandler: HandlerDigest,
+	})
+	c.Register(Spec{
+		Name:     "websub",
+		Summary:  "Receive pushed updates from WebSub hubs",
+		Usage:    []string{"serve <listen_addr> <callback_base_url>", "list"},
+		Examples: []string{"websub serve :8080 https://goflux.example.com"},
+		Handler:  HandlerWebSub,
+	})
+	c.Register(Spec{
+		Name:    "feedhttp",
+		Summary: "Set credentials, headers and proxies used to fetch a feed",
+		Usage: []string{
+			"<feed_url> show",
+			"<feed_url> auth basic <username> <password> | auth bearer <token> | auth none",
+			"<feed_url> header|cookie <name> [value]",
+			"<feed_url> user-agent [value]",
+			"<feed_url> proxy [url]",
+			"<feed_url> clear",
+		},
+		Examples:    []string{"feedhttp https://example.com/feed.xml header X-Api-Key secret"},
+		UserHandler: HandlerFeedHTTP,
+		Complete:    completeFeedHTTP,
+	})
+
+	return c
+}
@@ -0,0 +1,222 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/twomotive/GoFlux/internal/state"
+	"golang.org/x/term"
+)
+
+const shellPrompt = "goflux> "
+
+// handlerShell reads commands in a loop, reusing one database connection
+// and session for all of them. On a terminal it offers line editing,
+// history and tab completion
+func (c *Commands) handlerShell(ctx context.Context, s *state.State, cmd Command) error {
+	if len(cmd.Args) != 0 {
+		return cmd.usageError()
+	}
+
+	readLine, closeInput, err := c.shellInput(ctx, s)
+	if err != nil {
+		return err
+	}
+	defer closeInput()
+
+	for {
+		line, err := readLine()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("cannot read command: %v", err)
+		}
+
+		words, err := splitWords(line)
+		if err != nil {
+			slog.Error(err.Error())
+			continue
+		}
+		if len(words) == 0 {
+			continue
+		}
+
+		switch words[0] {
+		case "exit", "quit":
+			return nil
+		case "shell":
+			slog.Error("already in a shell")
+			continue
+		}
+
+		// Ctrl-C stops the running command but not the shell
+		cmdCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+		err = c.Run(cmdCtx, s, Command{Name: words[0], Args: words[1:]})
+		stop()
+		if err != nil {
+			slog.Error(err.Error())
+		}
+
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+// shellInput returns a line reader for the shell. Piped input is read
+// plainly, a terminal gets an editor with history and completion
+func (c *Commands) shellInput(ctx context.Context, s *state.State) (func() (string, error), func(), error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return func() (string, error) {
+			line, err := stdin.ReadString('\n')
+			if err == io.EOF && line != "" {
+				err = nil
+			}
+			return line, err
+		}, func() {}, nil
+	}
+
+	terminal := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, os.Stdout}, shellPrompt)
+	terminal.AutoCompleteCallback = func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' {
+			return "", 0, false
+		}
+		return c.completeLine(ctx, s, line, pos)
+	}
+
+	fmt.Println("GoFlux shell. Type 'help' for commands, 'exit' or Ctrl-D to leave.")
+
+	// The terminal is only raw while a line is edited, so command
+	// output and password prompts behave as usual
+	readLine := func() (string, error) {
+		oldState, err := term.MakeRaw(fd)
+		if err != nil {
+			return "", err
+		}
+		defer term.Restore(fd, oldState)
+
+		if width, height, err := term.GetSize(fd); err == nil {
+			terminal.SetSize(width, height)
+		}
+		return terminal.ReadLine()
+	}
+	return readLine, func() { fmt.Println() }, nil
+}
+
+// completeLine completes the word before the cursor to the longest
+// prefix shared by all candidates
+func (c *Commands) completeLine(ctx context.Context, s *state.State, line string, pos int) (string, int, bool) {
+	before, after := line[:pos], line[pos:]
+
+	words, err := splitWords(before)
+	if err != nil {
+		return "", 0, false
+	}
+	current := ""
+	if len(words) > 0 && !strings.HasSuffix(before, " ") {
+		current = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+
+	// Words with quotes or escapes are left alone
+	if !strings.HasSuffix(before, current) {
+		return "", 0, false
+	}
+
+	var values []string
+	for _, candidate := range c.complete(ctx, s, append(words, current)) {
+		value, _, _ := strings.Cut(candidate, "\t")
+		values = append(values, value)
+	}
+	if len(values) == 0 {
+		return "", 0, false
+	}
+
+	completion := values[0]
+	for _, value := range values[1:] {
+		completion = completion[:commonPrefixLength(completion, value)]
+	}
+	if len(values) == 1 {
+		completion += " "
+	}
+	if completion == current {
+		return "", 0, false
+	}
+
+	before = strings.TrimSuffix(before, current) + completion
+	return before + after, len(before), true
+}
+
+func commonPrefixLength(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+// splitWords splits a command line into words like a POSIX shell:
+// words are separated by spaces, quotes group them and a backslash
+// escapes the next character outside single quotes
+func splitWords(line string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+
+	runes := []rune(strings.TrimRight(line, "\r\n"))
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\':
+			if i+1 >= len(runes) {
+				return nil, fmt.Errorf("line ends with an escape")
+			}
+			i++
+			word.WriteRune(runes[i])
+			inWord = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
@@ -0,0 +1,178 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/twomotive/GoFlux/internal/database"
+	"github.com/twomotive/GoFlux/internal/rssfeeds"
+	"github.com/twomotive/GoFlux/internal/state"
+)
+
+const (
+	// storyWindow is how far back title matching looks for the same story
+	storyWindow = 72 * time.Hour
+	// minTitleSimilarity is the share of words two titles need in common
+	minTitleSimilarity = 0.8
+	// minTitleWords keeps short titles like "Weekly update" from matching
+	minTitleWords = 4
+)
+
+// resolveCanonical returns the canonical URL of a new article, or its
+// own URL when it cannot be resolved. The feed's credentials, headers
+// and cookies are only sent to the feed's own host
+func resolveCanonical(ctx context.Context, s *state.State, feed database.Feed, opts rssfeeds.FetchOptions, link string) string {
+	if s.Cfg.Crawl.SkipCanonical || s.Fetcher == nil {
+		return link
+	}
+	if !sameHost(feed.Url, link) {
+		opts = rssfeeds.FetchOptions{UserAgent: opts.UserAgent, ProxyURL: opts.ProxyURL}
+	}
+
+	canonical, err := s.Fetcher.ResolveCanonical(ctx, link, opts)
+	if err != nil {
+		slog.Debug("cannot resolve canonical url", "url", redactURL(link), "error", err)
+		return link
+	}
+	return canonical
+}
+
+// sameHost reports whether two URLs are on the same host
+func sameHost(a, b string) bool {
+	ua, err := url.Parse(a)
+	if err != nil {
+		return false
+	}
+	ub, err := url.Parse(b)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(ua.Hostname(), ub.Hostname())
+}
+
+// resolvePostCanonical records the canonical URL of a saved post. A
+// post that started a story of its own joins the story another feed's
+// post has under that URL
+func resolvePostCanonical(ctx context.Context, s *state.State, feed database.Feed, opts rssfeeds.FetchOptions, post database.Post) {
+	canonicalURL := normalizeURL(s, resolveCanonical(ctx, s, feed, opts, post.Url))
+	if canonicalURL == post.NormalizedUrl {
+		return
+	}
+
+	storyID := post.StoryID
+	if storyID.UUID == post.ID {
+		if story := storyByURL(ctx, s, feed.ID, canonicalURL); story.Valid {
+			storyID = story
+		}
+	}
+
+	err := s.DB.UpdatePostCanonical(ctx, database.UpdatePostCanonicalParams{
+		ID:           post.ID,
+		CanonicalUrl: sql.NullString{String: canonicalURL, Valid: true},
+		StoryID:      storyID,
+	})
+	if err != nil {
+		slog.Error("cannot save canonical url", "post_id", post.ID, "error", err)
+	}
+}
+
+// findStory groups a new post with the same story from other feeds,
+// matching its URL first and then similar titles. A post that matches
+// nothing starts a story of its own
+func findStory(ctx context.Context, s *state.State, feedID, postID uuid.UUID, link, title string) uuid.UUID {
+	if story := storyByURL(ctx, s, feedID, link); story.Valid {
+		return story.UUID
+	}
+
+	words := titleWords(title)
+	if len(words) < minTitleWords {
+		return postID
+	}
+
+	candidates, err := s.DB.GetRecentPostTitles(ctx, database.GetRecentPostTitlesParams{
+		FeedID:    feedID,
+		CreatedAt: time.Now().UTC().Add(-storyWindow),
+	})
+	if err != nil {
+		slog.Warn("cannot get recent posts for story matching", "feed_id", feedID, "error", err)
+		return postID
+	}
+
+	var best uuid.NullUUID
+	bestScore := minTitleSimilarity
+	for _, candidate := range candidates {
+		if !candidate.StoryID.Valid {
+			continue
+		}
+		if score := titleSimilarity(words, titleWords(candidate.Title)); score >= bestScore {
+			best, bestScore = candidate.StoryID, score
+		}
+	}
+	if best.Valid {
+		return best.UUID
+	}
+	return postID
+}
+
+// storyByURL finds the story of another feed's post known by the
+// normalized URL u, either as its own or as its canonical URL
+func storyByURL(ctx context.Context, s *state.State, feedID uuid.UUID, u string) uuid.NullUUID {
+	story, err := s.DB.FindStoryByUrl(ctx, database.FindStoryByUrlParams{Url: u, FeedID: feedID})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		slog.Warn("cannot look up story by url", "url", redactURL(u), "error", err)
+	}
+	return story
+}
+
+// titleWords returns the distinct lower-cased words of a title
+func titleWords(title string) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		words[word] = true
+	}
+	return words
+}
+
+// titleSimilarity is the Dice coefficient of two word sets, from 0 for
+// nothing in common to 1 for the same words
+func titleSimilarity(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	common := 0
+	for word := range a {
+		if b[word] {
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(a)+len(b))
+}
+
+// alsoIn names the other feeds carrying the post's story
+func alsoIn(ctx context.Context, s *state.State, post database.GetPostsByUserRow) []string {
+	if !post.StoryID.Valid {
+		return nil
+	}
+
+	feeds, err := s.DB.GetStoryFeeds(ctx, post.StoryID)
+	if err != nil {
+		slog.Warn("cannot get feeds of story", "post_id", post.ID, "error", err)
+		return nil
+	}
+	var names []string
+	for _, feed := range feeds {
+		if feed.ID != post.FeedID {
+			names = append(names, feed.Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,126 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/twomotive/GoFlux/internal/database"
+	"github.com/twomotive/GoFlux/internal/state"
+	"github.com/twomotive/GoFlux/internal/urlnorm"
+)
+
+// normalizeURL returns the form that identifies a feed or post URL, or
+// the URL unchanged when it is not absolute
+func normalizeURL(s *state.State, raw string) string {
+	normalized, err := urlnorm.New(s.Cfg.Crawl.TrackingParams()).Normalize(raw)
+	if err != nil {
+		return raw
+	}
+	return normalized
+}
+
+// feedByURL finds a feed by its URL as given or normalized
+func feedByURL(ctx context.Context, s *state.State, raw string) (database.Feed, error) {
+	return s.DB.GetFeedByUrl(ctx, database.GetFeedByUrlParams{Url: raw, NormalizedUrl: normalizeURL(s, raw)})
+}
+
+// postByURL finds a post by its URL as given or normalized
+func postByURL(ctx context.Context, s *state.State, raw string) (database.Post, error) {
+	return s.DB.GetPostByUrl(ctx, database.GetPostByUrlParams{Url: raw, NormalizedUrl: normalizeURL(s, raw)})
+}
+
+// normalizedURLIndexVersion is the migration making normalized URLs
+// unique. Stored URLs are normalized right before it runs
+const normalizedURLIndexVersion = 21
+
+// storedURL is a feed or post URL with its stored normalized form, empty
+// when it was never normalized
+type storedURL struct {
+	id         uuid.UUID
+	url        string
+	normalized string
+}
+
+// normalizeStoredURLs normalizes the feed and post URLs stored before
+// the normalized_url column existed. When several URLs normalize to the
+// same one, only one of them gets it: the URL already in that form, or
+// else the oldest. The others keep their URL as is, so they stay
+// distinct once the unique index is added
+func normalizeStoredURLs(ctx context.Context, s *state.State) error {
+	feeds, err := s.DB.GetFeedUrls(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot get feeds to normalize: %v", err)
+	}
+	feedURLs := make([]storedURL, len(feeds))
+	for i, feed := range feeds {
+		feedURLs[i] = storedURL{id: feed.ID, url: feed.Url, normalized: feed.NormalizedUrl}
+	}
+	feedsUpdated, err := assignNormalizedURLs(s, feedURLs, func(id uuid.UUID, normalized string) error {
+		return s.DB.SetFeedNormalizedUrl(ctx, database.SetFeedNormalizedUrlParams{ID: id, NormalizedUrl: normalized})
+	})
+	if err != nil {
+		return fmt.Errorf("cannot normalize feed url: %v", err)
+	}
+
+	posts, err := s.DB.GetPostUrls(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot get posts to normalize: %v", err)
+	}
+	postURLs := make([]storedURL, len(posts))
+	for i, post := range posts {
+		postURLs[i] = storedURL{id: post.ID, url: post.Url, normalized: post.NormalizedUrl}
+	}
+	postsUpdated, err := assignNormalizedURLs(s, postURLs, func(id uuid.UUID, normalized string) error {
+		return s.DB.SetPostNormalizedUrl(ctx, database.SetPostNormalizedUrlParams{ID: id, NormalizedUrl: normalized})
+	})
+	if err != nil {
+		return fmt.Errorf("cannot normalize post url: %v", err)
+	}
+
+	if updated := feedsUpdated + postsUpdated; updated > 0 {
+		fmt.Printf("Normalized %d stored URLs\n", updated)
+	}
+	return nil
+}
+
+// assignNormalizedURLs saves a normalized form for every URL without
+// one, never giving the same form to two URLs. It returns how many were
+// saved
+func assignNormalizedURLs(s *state.State, urls []storedURL, save func(id uuid.UUID, normalized string) error) (int, error) {
+	taken := make(map[string]bool)
+	var pending []storedURL
+	for _, u := range urls {
+		if u.normalized != "" {
+			taken[u.normalized] = true
+			continue
+		}
+		u.normalized = normalizeURL(s, u.url)
+		pending = append(pending, u)
+	}
+
+	// URLs already in normalized form claim it first, then the oldest
+	slices.SortStableFunc(pending, func(a, b storedURL) int {
+		switch {
+		case a.url == a.normalized && b.url != b.normalized:
+			return -1
+		case a.url != a.normalized && b.url == b.normalized:
+			return 1
+		}
+		return 0
+	})
+
+	for _, u := range pending {
+		if taken[u.normalized] {
+			slog.Warn("url normalizes to the same as another, keeping it as is", "url", redactURL(u.url), "normalized", redactURL(u.normalized))
+			u.normalized = u.url
+		}
+		taken[u.normalized] = true
+		if err := save(u.id, u.normalized); err != nil {
+			return 0, err
+		}
+	}
+	return len(pending), nil
+}
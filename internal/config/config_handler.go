@@ -1,80 +1,471 @@
 package config
 
 import (
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/twomotive/GoFlux/internal/secrets"
 )
 
 const (
-	// configFileName is the name of the db configuration file stored in the user's home directory
+	// configFileName is the legacy db configuration file stored in the user's home directory
 	configFileName = ".gatorconfig.json"
-	// configFileMode defines the file permissions (read/write for owner, read for group and others)
-	configFileMode = 0644
+	// xdgDirName and xdgFileName form the config path below the XDG config directory
+	xdgDirName  = "goflux"
+	xdgFileName = "config.json"
+	// configFileMode keeps the file private to its owner, as it holds
+	// the secret key, session and API tokens and the SMTP password
+	configFileMode = 0600
+	// envPrefix starts every environment variable overriding a config key
+	envPrefix = "GOFLUX_"
+	// profilesKey holds the named profiles inside the config file
+	profilesKey = "profiles"
 )
 
 // Config represents the application configuration structure
 // that is serialized to and deserialized from the config file
 type Config struct {
-	DBUrl           string `json:"db_url"`            // Database connection URL
-	CurrentUsername string `json:"current_user_name"` // Currently active username
+	DBUrl        string      `json:"db_url"`        // Database connection URL
+	SessionToken string      `json:"session_token"` // Token of the logged in session
+	Token        string      `json:"token"`         // API token, used instead of the session when set
+	SMTP         SMTPConfig  `json:"smtp"`          // Outgoing mail settings for digests
+	SecretKey    string      `json:"secret_key"`    // Base64 key encrypting stored feed credentials
+	Crawl        CrawlConfig `json:"crawl"`         // Per-host politeness settings for fetching
+
+	path       string         // File the configuration was loaded from and is saved to
+	profile    string         // Active profile, empty for the top level
+	newProfile bool           // Whether the active profile may be missing from the file
+	doc        map[string]any // Config file contents as stored on disk
+}
+
+// Options selects which config file and profile Load uses
+type Options struct {
+	Path    string // Explicit config file, overrides GOFLUX_CONFIG and the default locations
+	Profile string // Named profile, overrides GOFLUX_PROFILE
+	// NewProfile accepts a profile missing from the file, so that
+	// 'config set' can create it
+	NewProfile bool
+}
+
+// CrawlConfig controls how politely feeds are fetched from each host
+type CrawlConfig struct {
+	MinHostInterval    string `json:"min_host_interval"`    // Duration between requests to one host, e.g. "5s"
+	MaxHostConcurrency int    `json:"max_host_concurrency"` // Parallel requests allowed per host
+	IgnoreRobots       bool   `json:"ignore_robots"`        // Skip robots.txt checks
+	FetchTimeout       string `json:"fetch_timeout"`        // Limit on a single feed request, e.g. "30s"
+	DrainTimeout       string `json:"drain_timeout"`        // Time agg may spend finishing work after a shutdown signal
+	LeaseDuration      string `json:"lease_duration"`       // How long a claimed feed stays reserved for one aggregator
+	SkipCanonical      bool   `json:"skip_canonical"`       // Do not fetch new articles to find their canonical URL
+	StripParams        string `json:"strip_params"`         // Comma separated query parameters removed from URLs besides utm_*, fbclid and gclid
 }
 
-// Read loads the configuration from the config file
+const (
+	// defaultMinHostInterval applies when crawl.min_host_interval is unset
+	defaultMinHostInterval = 2 * time.Second
+	// defaultFetchTimeout applies when crawl.fetch_timeout is unset
+	defaultFetchTimeout = 30 * time.Second
+	// defaultDrainTimeout applies when crawl.drain_timeout is unset
+	defaultDrainTimeout = 30 * time.Second
+	// defaultLeaseDuration applies when crawl.lease_duration is unset
+	defaultLeaseDuration = 10 * time.Minute
+)
+
+// HostInterval parses the configured minimum interval between requests to one host
+func (c CrawlConfig) HostInterval() (time.Duration, error) {
+	if c.MinHostInterval == "" {
+		return defaultMinHostInterval, nil
+	}
+	interval, err := time.ParseDuration(c.MinHostInterval)
+	if err != nil {
+		return 0, fmt.Errorf("invalid crawl.min_host_interval: %v", err)
+	}
+	return interval, nil
+}
+
+// Timeout parses the configured limit on a single feed request
+func (c CrawlConfig) Timeout() (time.Duration, error) {
+	if c.FetchTimeout == "" {
+		return defaultFetchTimeout, nil
+	}
+	timeout, err := time.ParseDuration(c.FetchTimeout)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("invalid crawl.fetch_timeout: %q", c.FetchTimeout)
+	}
+	return timeout, nil
+}
+
+// Lease parses how long a claimed feed stays reserved. It must outlast a
+// whole fetch, or a second aggregator may take the feed over mid-fetch
+func (c CrawlConfig) Lease() (time.Duration, error) {
+	if c.LeaseDuration == "" {
+		return defaultLeaseDuration, nil
+	}
+	lease, err := time.ParseDuration(c.LeaseDuration)
+	if err != nil || lease <= 0 {
+		return 0, fmt.Errorf("invalid crawl.lease_duration: %q", c.LeaseDuration)
+	}
+	return lease, nil
+}
+
+// DrainPeriod parses how long agg may keep working after a shutdown signal
+func (c CrawlConfig) DrainPeriod() (time.Duration, error) {
+	if c.DrainTimeout == "" {
+		return defaultDrainTimeout, nil
+	}
+	drain, err := time.ParseDuration(c.DrainTimeout)
+	if err != nil || drain < 0 {
+		return 0, fmt.Errorf("invalid crawl.drain_timeout: %q", c.DrainTimeout)
+	}
+	return drain, nil
+}
+
+// TrackingParams lists the extra query parameters to strip from URLs
+func (c CrawlConfig) TrackingParams() []string {
+	var params []string
+	for _, param := range strings.Split(c.StripParams, ",") {
+		if param = strings.TrimSpace(param); param != "" {
+			params = append(params, param)
+		}
+	}
+	return params
+}
+
+// SMTPConfig holds the settings used to deliver email digests
+type SMTPConfig struct {
+	Host     string `json:"host"`     // SMTP server host name
+	Port     int    `json:"port"`     // SMTP server port, defaults to 25
+	Username string `json:"username"` // Optional username for PLAIN auth
+	Password string `json:"password"` // Optional password for PLAIN auth
+	From     string `json:"from"`     // Sender address used in the From header
+}
+
+// Read loads the configuration from the default config file
 // Returns a pointer to the Config struct and any error encountered
 func Read() (*Config, error) {
-	path, err := getConfigFilePath()
+	return Load(Options{})
+}
+
+// Load builds the configuration in layers: the config file, then the
+// selected profile inside it, then GOFLUX_* environment variables.
+// A missing config file is not an error, the result is simply empty
+func Load(opts Options) (*Config, error) {
+	path, err := resolveConfigPath(opts.Path)
 	if err != nil {
 		return nil, fmt.Errorf("cannot get path: %v", err)
 	}
+
+	doc := make(map[string]any)
 	jsonData, err := os.ReadFile(path)
-	if err != nil {
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
 		return nil, fmt.Errorf("file cannot read (%s): %v", path, err)
 	}
+	if err == nil {
+		if err := json.Unmarshal(jsonData, &doc); err != nil {
+			return nil, fmt.Errorf("JSON Unmarshal error: %v", err)
+		}
+	}
+
+	profile := opts.Profile
+	if profile == "" {
+		profile = os.Getenv(envPrefix + "PROFILE")
+	}
+
+	cfg := &Config{path: path, profile: profile, newProfile: opts.NewProfile, doc: doc}
+	if err := cfg.resolve(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// resolve recomputes the effective values from the file contents,
+// the active profile and the environment
+func (cfg *Config) resolve() error {
+	settings := defaultSettings()
+
+	file := make(map[string]any, len(cfg.doc))
+	for key, value := range cfg.doc {
+		if key != profilesKey {
+			file[key] = value
+		}
+	}
+	mergeSettings(settings, file)
 
-	var config Config
+	if cfg.profile != "" {
+		profile, ok := cfg.profileDoc(false)
+		if !ok && !cfg.newProfile {
+			return fmt.Errorf("profile '%s' not found in %s", cfg.profile, cfg.path)
+		}
+		mergeSettings(settings, profile)
+	}
 
-	err = json.Unmarshal(jsonData, &config)
+	for _, key := range Keys() {
+		value, ok := os.LookupEnv(envName(key))
+		if !ok {
+			continue
+		}
+		typed, err := coerce(key, value)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %v", envName(key), err)
+		}
+		setPath(settings, key, typed)
+	}
+
+	jsonData, err := json.Marshal(settings)
 	if err != nil {
-		return nil, fmt.Errorf("JSON Unmarshal error: %v", err)
+		return fmt.Errorf("JSON Marshal error: %v", err)
+	}
+
+	var effective Config
+	if err := json.Unmarshal(jsonData, &effective); err != nil {
+		return fmt.Errorf("JSON Unmarshal error: %v", err)
+	}
+	effective.path, effective.profile, effective.newProfile, effective.doc = cfg.path, cfg.profile, cfg.newProfile, cfg.doc
+	*cfg = effective
+
+	return nil
+}
+
+// Path returns the config file in use
+func (cfg *Config) Path() string {
+	return cfg.path
+}
+
+// Profile returns the active profile, empty for none
+func (cfg *Config) Profile() string {
+	return cfg.profile
+}
+
+// Exists reports whether the config file is present on disk
+func (cfg *Config) Exists() bool {
+	_, err := os.Stat(cfg.path)
+	return err == nil
+}
+
+// Keys lists every setting addressable with Get and Set, in dotted form
+func Keys() []string {
+	var keys []string
+	collectKeys(defaultSettings(), "", &keys)
+	sort.Strings(keys)
+	return keys
+}
+
+// Get returns the effective value of a dotted key such as "smtp.host"
+func (cfg *Config) Get(key string) (string, error) {
+	jsonData, err := json.Marshal(cfg)
+	if err != nil {
+		return "", fmt.Errorf("JSON Marshal error: %v", err)
+	}
+	var settings map[string]any
+	if err := json.Unmarshal(jsonData, &settings); err != nil {
+		return "", fmt.Errorf("JSON Unmarshal error: %v", err)
+	}
+
+	value, ok := getPath(settings, key)
+	if !ok {
+		return "", fmt.Errorf("unknown config key '%s'", key)
+	}
+	if _, isMap := value.(map[string]any); isMap {
+		return "", fmt.Errorf("'%s' is a section, use one of its keys", key)
+	}
+	return fmt.Sprint(value), nil
+}
+
+// Set stores a value in the config file, inside the active profile
+// if there is one, and persists the file
+func (cfg *Config) Set(key, value string) error {
+	typed, err := coerce(key, value)
+	if err != nil {
+		return err
+	}
+
+	target := cfg.doc
+	if cfg.profile != "" {
+		target, _ = cfg.profileDoc(true)
 	}
+	setPath(target, key, typed)
 
-	return &config, nil
+	if err := cfg.resolve(); err != nil {
+		return err
+	}
+	return write(cfg)
 }
 
-// SetUser updates the current username in the configuration
+// SetSession stores the session token of the logged in user
 // and persists the changes to the config file
-func (cfg *Config) SetUser(username string) error {
-	cfg.CurrentUsername = username
+func (cfg *Config) SetSession(token string) error {
+	return cfg.Set("session_token", token)
+}
+
+// Init creates the config file with the given settings at the resolved path.
+// It refuses to overwrite an existing file
+func (cfg *Config) Init(settings map[string]string) error {
+	if cfg.Exists() {
+		return fmt.Errorf("config file %s already exists", cfg.path)
+	}
+	cfg.doc = make(map[string]any)
+	for key, value := range settings {
+		typed, err := coerce(key, value)
+		if err != nil {
+			return err
+		}
+		setPath(cfg.doc, key, typed)
+	}
+
+	if err := cfg.resolve(); err != nil {
+		return err
+	}
 	return write(cfg)
 }
 
+// ErrNoSecretKey is returned by EncryptionKey when secret_key is not set
+var ErrNoSecretKey = errors.New("secret_key is not set in the config")
+
+// EncryptionKey returns the key used to encrypt stored credentials
+func (cfg *Config) EncryptionKey() ([]byte, error) {
+	if cfg.SecretKey == "" {
+		return nil, ErrNoSecretKey
+	}
+
+	key, err := base64.StdEncoding.DecodeString(cfg.SecretKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secret_key in config: %v", err)
+	}
+	if len(key) != secrets.KeySize {
+		return nil, fmt.Errorf("invalid secret_key in config: want %d bytes, got %d", secrets.KeySize, len(key))
+	}
+
+	return key, nil
+}
+
+// NewEncryptionKey generates a key for encrypting stored credentials
+// and saves it as secret_key. Credentials encrypted with an earlier key
+// cannot be read with the new one
+func (cfg *Config) NewEncryptionKey() ([]byte, error) {
+	key, err := secrets.NewKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Set("secret_key", base64.StdEncoding.EncodeToString(key)); err != nil {
+		return nil, fmt.Errorf("cannot save generated secret key: %v", err)
+	}
+	return key, nil
+}
+
+// profileDoc returns the active profile's section of the config file,
+// creating it when create is set
+func (cfg *Config) profileDoc(create bool) (map[string]any, bool) {
+	profiles, ok := cfg.doc[profilesKey].(map[string]any)
+	if !ok {
+		if !create {
+			return nil, false
+		}
+		profiles = make(map[string]any)
+		cfg.doc[profilesKey] = profiles
+	}
+
+	profile, ok := profiles[cfg.profile].(map[string]any)
+	if !ok {
+		if !create {
+			return nil, false
+		}
+		profile = make(map[string]any)
+		profiles[cfg.profile] = profile
+	}
+
+	return profile, true
+}
+
 // write persists the configuration to the config file
-// it serializes the Config struct to JSON with indentation
+// it serializes the file contents to JSON with indentation
 func write(cfg *Config) error {
 	// Convert config to formatted JSON
-	jsonDataIndent, err := json.MarshalIndent(cfg, "", "  ")
+	jsonDataIndent, err := json.MarshalIndent(cfg.doc, "", "  ")
 	if err != nil {
 		return fmt.Errorf("JSON MarshalIndent error: %v", err)
 	}
 
-	// Get the full path to the config file
-	path, err := getConfigFilePath()
-	if err != nil {
-		return fmt.Errorf("cannot get config file path: %v", err)
+	// Replace the file a symlinked config points to, not the link
+	path := cfg.path
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+
+	// The XDG config directory may not exist yet on a fresh machine
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("cannot create config directory: %v", err)
 	}
 
-	// Write the JSON data to the config file
-	err = os.WriteFile(path, jsonDataIndent, configFileMode)
+	// Write a private temporary file and move it over the config, so the
+	// secrets are never readable by others, even when older versions
+	// created the file readable by everyone
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
 	if err != nil {
 		return fmt.Errorf("cannot write config file: %v", err)
 	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(configFileMode); err != nil {
+		tmp.Close()
+		return fmt.Errorf("cannot restrict config file permissions: %v", err)
+	}
+	if _, err := tmp.Write(jsonDataIndent); err != nil {
+		tmp.Close()
+		return fmt.Errorf("cannot write config file: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("cannot write config file: %v", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("cannot write config file: %v", err)
+	}
 
 	return nil
 }
 
-// getConfigFilePath returns the full path to the configuration file
+// resolveConfigPath picks the config file: an explicit path, then
+// GOFLUX_CONFIG, then the XDG location, falling back to the legacy
+// ~/.gatorconfig.json when only that one exists
+func resolveConfigPath(explicit string) (string, error) {
+	if explicit != "" {
+		return explicit, nil
+	}
+	if path := os.Getenv(envPrefix + "CONFIG"); path != "" {
+		return path, nil
+	}
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot get config directory: %v", err)
+	}
+	xdgPath := filepath.Join(configDir, xdgDirName, xdgFileName)
+	if _, err := os.Stat(xdgPath); err == nil {
+		return xdgPath, nil
+	}
+
+	legacyPath, err := getConfigFilePath()
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(legacyPath); err == nil {
+		return legacyPath, nil
+	}
+
+	return xdgPath, nil
+}
+
+// getConfigFilePath returns the full path to the legacy configuration file
 // located in the user's home directory
 func getConfigFilePath() (string, error) {
 	homeDir, err := os.UserHomeDir()
@@ -86,3 +477,103 @@ func getConfigFilePath() (string, error) {
 
 	return fullFilePath, nil
 }
+
+// defaultSettings returns the zero Config as a generic JSON document,
+// which also serves as the schema of known keys and their types
+func defaultSettings() map[string]any {
+	jsonData, _ := json.Marshal(Config{})
+	var settings map[string]any
+	json.Unmarshal(jsonData, &settings)
+	return settings
+}
+
+// envName maps a dotted key to its environment variable, e.g.
+// "smtp.host" becomes GOFLUX_SMTP_HOST
+func envName(key string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// coerce converts a string to the JSON type the key expects
+func coerce(key, value string) (any, error) {
+	current, ok := getPath(defaultSettings(), key)
+	if !ok {
+		return nil, fmt.Errorf("unknown config key '%s'", key)
+	}
+
+	switch current.(type) {
+	case float64:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a number", key)
+		}
+		return float64(n), nil
+	case bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be true or false", key)
+		}
+		return b, nil
+	case map[string]any:
+		return nil, fmt.Errorf("'%s' is a section, use one of its keys", key)
+	default:
+		return value, nil
+	}
+}
+
+func collectKeys(settings map[string]any, prefix string, keys *[]string) {
+	for key, value := range settings {
+		if nested, ok := value.(map[string]any); ok {
+			collectKeys(nested, prefix+key+".", keys)
+			continue
+		}
+		*keys = append(*keys, prefix+key)
+	}
+}
+
+// mergeSettings copies src over dst, descending into nested sections.
+// Sections are copied rather than shared, so changing dst never alters
+// the config file contents src came from
+func mergeSettings(dst, src map[string]any) {
+	for key, value := range src {
+		nested, isMap := value.(map[string]any)
+		if !isMap {
+			dst[key] = value
+			continue
+		}
+		existing, existingIsMap := dst[key].(map[string]any)
+		if !existingIsMap {
+			existing = make(map[string]any, len(nested))
+			dst[key] = existing
+		}
+		mergeSettings(existing, nested)
+	}
+}
+
+func getPath(settings map[string]any, key string) (any, bool) {
+	var current any = settings
+	for _, part := range strings.Split(key, ".") {
+		section, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		current, ok = section[part]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func setPath(settings map[string]any, key string, value any) {
+	parts := strings.Split(key, ".")
+	section := settings
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := section[part].(map[string]any)
+		if !ok {
+			next = make(map[string]any)
+			section[part] = next
+		}
+		section = next
+	}
+	section[parts[len(parts)-1]] = value
+}
@@ -0,0 +1,317 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testConfig = `{
+  "db_url": "postgres://base",
+  "smtp": {"host": "mail.base", "port": 25, "from": "goflux@base"},
+  "profiles": {
+    "staging": {
+      "db_url": "postgres://staging",
+      "smtp": {"host": "mail.staging"}
+    }
+  }
+}`
+
+// clearEnv hides GOFLUX_* variables of the environment running the tests
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		if strings.HasPrefix(name, envPrefix) {
+			t.Setenv(name, "")
+			os.Unsetenv(name)
+		}
+	}
+}
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadLayers(t *testing.T) {
+	tests := []struct {
+		name         string
+		profile      string
+		env          map[string]string
+		dbURL        string
+		smtpHost     string
+		smtpPort     int
+		smtpFrom     string
+		ignoreRobots bool
+	}{
+		{
+			name:  "file",
+			dbURL: "postgres://base", smtpHost: "mail.base", smtpPort: 25, smtpFrom: "goflux@base",
+		},
+		{
+			name:    "profile merges into sections",
+			profile: "staging",
+			dbURL:   "postgres://staging", smtpHost: "mail.staging", smtpPort: 25, smtpFrom: "goflux@base",
+		},
+		{
+			name:  "profile from the environment",
+			env:   map[string]string{"GOFLUX_PROFILE": "staging"},
+			dbURL: "postgres://staging", smtpHost: "mail.staging", smtpPort: 25, smtpFrom: "goflux@base",
+		},
+		{
+			name:    "environment overrides the profile",
+			profile: "staging",
+			env: map[string]string{
+				"GOFLUX_DB_URL":              "postgres://env",
+				"GOFLUX_SMTP_PORT":           "2525",
+				"GOFLUX_CRAWL_IGNORE_ROBOTS": "true",
+			},
+			dbURL: "postgres://env", smtpHost: "mail.staging", smtpPort: 2525, smtpFrom: "goflux@base",
+			ignoreRobots: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			cfg, err := Load(Options{Path: writeConfig(t, testConfig), Profile: tt.profile})
+			if err != nil {
+				t.Fatalf("Load returned error: %v", err)
+			}
+			if cfg.DBUrl != tt.dbURL {
+				t.Errorf("DBUrl = %q, want %q", cfg.DBUrl, tt.dbURL)
+			}
+			if cfg.SMTP.Host != tt.smtpHost {
+				t.Errorf("SMTP.Host = %q, want %q", cfg.SMTP.Host, tt.smtpHost)
+			}
+			if cfg.SMTP.Port != tt.smtpPort {
+				t.Errorf("SMTP.Port = %d, want %d", cfg.SMTP.Port, tt.smtpPort)
+			}
+			if cfg.SMTP.From != tt.smtpFrom {
+				t.Errorf("SMTP.From = %q, want %q", cfg.SMTP.From, tt.smtpFrom)
+			}
+			if cfg.Crawl.IgnoreRobots != tt.ignoreRobots {
+				t.Errorf("Crawl.IgnoreRobots = %v, want %v", cfg.Crawl.IgnoreRobots, tt.ignoreRobots)
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		profile string
+		env     map[string]string
+		want    string
+	}{
+		{name: "unknown profile", content: testConfig, profile: "prod", want: "profile 'prod' not found"},
+		{name: "invalid number", content: testConfig, env: map[string]string{"GOFLUX_SMTP_PORT": "25a"}, want: "invalid GOFLUX_SMTP_PORT"},
+		{name: "invalid boolean", content: testConfig, env: map[string]string{"GOFLUX_CRAWL_SKIP_CANONICAL": "maybe"}, want: "invalid GOFLUX_CRAWL_SKIP_CANONICAL"},
+		{name: "malformed file", content: `{"db_url": `, want: "JSON Unmarshal error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			_, err := Load(Options{Path: writeConfig(t, tt.content), Profile: tt.profile})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	clearEnv(t)
+	path := filepath.Join(t.TempDir(), "goflux", "config.json")
+
+	cfg, err := Load(Options{Path: path})
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if cfg.Exists() || cfg.DBUrl != "" {
+		t.Errorf("Load of a missing file = exists %v, db_url %q, want an empty config", cfg.Exists(), cfg.DBUrl)
+	}
+
+	// Saving creates the directory and a private file
+	if err := cfg.Set("db_url", "postgres://new"); err != nil {
+		t.Fatalf("Set returned error: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("config file was not written: %v", err)
+	}
+	if info.Mode().Perm() != configFileMode {
+		t.Errorf("config file mode = %v, want %v", info.Mode().Perm(), os.FileMode(configFileMode))
+	}
+}
+
+func TestWriteRestrictsExistingFile(t *testing.T) {
+	clearEnv(t)
+	dir := t.TempDir()
+	target := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(target, []byte(testConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "link.json")
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(Options{Path: link})
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if err := cfg.SetSession("token"); err != nil {
+		t.Fatalf("SetSession returned error: %v", err)
+	}
+
+	info, err := os.Lstat(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		t.Error("config symlink was replaced by a file")
+	}
+	info, err = os.Stat(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != configFileMode {
+		t.Errorf("config file mode = %v, want %v", info.Mode().Perm(), os.FileMode(configFileMode))
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("config directory holds %d files, want no temporary files left", len(entries))
+	}
+}
+
+func TestSetInProfile(t *testing.T) {
+	clearEnv(t)
+	path := writeConfig(t, testConfig)
+
+	cfg, err := Load(Options{Path: path, Profile: "dev", NewProfile: true})
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if err := cfg.Set("smtp.port", "2525"); err != nil {
+		t.Fatalf("Set returned error: %v", err)
+	}
+	if cfg.SMTP.Port != 2525 || cfg.SMTP.Host != "mail.base" {
+		t.Errorf("effective smtp = %s:%d, want mail.base:2525", cfg.SMTP.Host, cfg.SMTP.Port)
+	}
+
+	jsonData, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		SMTP     map[string]any            `json:"smtp"`
+		Profiles map[string]map[string]any `json:"profiles"`
+	}
+	if err := json.Unmarshal(jsonData, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.SMTP["port"] != float64(25) {
+		t.Errorf("top level smtp.port = %v, want it unchanged at 25", doc.SMTP["port"])
+	}
+	dev, _ := doc.Profiles["dev"]["smtp"].(map[string]any)
+	if dev["port"] != float64(2525) {
+		t.Errorf("profile dev smtp.port = %v, want 2525", dev["port"])
+	}
+	if _, ok := doc.Profiles["staging"]; !ok {
+		t.Error("profile staging was dropped from the file")
+	}
+}
+
+func TestSetKeepsEnvironmentOut(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("GOFLUX_SMTP_HOST", "mail.env")
+	path := writeConfig(t, testConfig)
+
+	cfg, err := Load(Options{Path: path, Profile: "staging"})
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if err := cfg.SetSession("token"); err != nil {
+		t.Fatalf("SetSession returned error: %v", err)
+	}
+
+	jsonData, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		SMTP     map[string]any            `json:"smtp"`
+		Profiles map[string]map[string]any `json:"profiles"`
+	}
+	if err := json.Unmarshal(jsonData, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.SMTP["host"] != "mail.base" {
+		t.Errorf("saved smtp.host = %v, want mail.base", doc.SMTP["host"])
+	}
+	staging, _ := doc.Profiles["staging"]["smtp"].(map[string]any)
+	if staging["host"] != "mail.staging" {
+		t.Errorf("saved staging smtp.host = %v, want mail.staging", staging["host"])
+	}
+	if doc.Profiles["staging"]["session_token"] != "token" {
+		t.Errorf("saved staging session_token = %v, want token", doc.Profiles["staging"]["session_token"])
+	}
+}
+
+func TestGetAndSetErrors(t *testing.T) {
+	clearEnv(t)
+	cfg, err := Load(Options{Path: writeConfig(t, testConfig)})
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+
+	if got, err := cfg.Get("smtp.host"); err != nil || got != "mail.base" {
+		t.Errorf("Get(smtp.host) = %q, %v, want mail.base", got, err)
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "get unknown key", err: getErr(cfg, "smtp.hostname"), want: "unknown config key 'smtp.hostname'"},
+		{name: "get section", err: getErr(cfg, "smtp"), want: "'smtp' is a section"},
+		{name: "set unknown key", err: cfg.Set("colour", "blue"), want: "unknown config key 'colour'"},
+		{name: "set section", err: cfg.Set("crawl", "x"), want: "'crawl' is a section"},
+		{name: "set number", err: cfg.Set("smtp.port", "many"), want: "smtp.port must be a number"},
+		{name: "set boolean", err: cfg.Set("crawl.ignore_robots", "yes please"), want: "crawl.ignore_robots must be true or false"},
+	}
+
+	for _, tt := range tests {
+		if tt.err == nil || !strings.Contains(tt.err.Error(), tt.want) {
+			t.Errorf("%s: error = %v, want one containing %q", tt.name, tt.err, tt.want)
+		}
+	}
+}
+
+func getErr(cfg *Config, key string) error {
+	_, err := cfg.Get(key)
+	return err
+}
@@ -75,17 +75,18 @@ DELETE FROM feed_follows
 WHERE
     feed_follows.user_id = $1
     AND feed_id IN (
-        SELECT id FROM feeds WHERE url = $2
+        SELECT id FROM feeds WHERE url = $2 OR normalized_url = $3
     )
 `
 
 type DeleteFeedFollowParams struct {
-	UserID uuid.UUID
-	Url    string
+	UserID        uuid.UUID
+	Url           string
+	NormalizedUrl string
 }
 
 func (q *Queries) DeleteFeedFollow(ctx context.Context, arg DeleteFeedFollowParams) error {
-	_, err := q.db.ExecContext(ctx, deleteFeedFollow, arg.UserID, arg.Url)
+	_, err := q.db.ExecContext(ctx, deleteFeedFollow, arg.UserID, arg.Url, arg.NormalizedUrl)
 	return err
 }
 
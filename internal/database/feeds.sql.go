@@ -7,31 +7,80 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const claimNextFeed = `-- name: ClaimNextFeed :one
+UPDATE feeds
+SET lease_owner = $1,
+    lease_expires_at = $2
+WHERE id = (
+    SELECT id FROM feeds
+    WHERE lease_expires_at IS NULL OR lease_expires_at < $3
+    ORDER BY last_fetched_at NULLS FIRST, updated_at ASC
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, lease_owner, lease_expires_at, title, site_url, description, language, image_url, generator, normalized_url
+`
+
+type ClaimNextFeedParams struct {
+	LeaseOwner     sql.NullString
+	LeaseExpiresAt sql.NullTime
+	Now            sql.NullTime
+}
+
+// Leases the most overdue feed to one aggregator. Rows locked by another
+// worker's claim are skipped, and expired leases of crashed workers are taken over
+func (q *Queries) ClaimNextFeed(ctx context.Context, arg ClaimNextFeedParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, claimNextFeed, arg.LeaseOwner, arg.LeaseExpiresAt, arg.Now)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.LeaseOwner,
+		&i.LeaseExpiresAt,
+		&i.Title,
+		&i.SiteUrl,
+		&i.Description,
+		&i.Language,
+		&i.ImageUrl,
+		&i.Generator,
+		&i.NormalizedUrl,
+	)
+	return i, err
+}
+
 const createFeed = `-- name: CreateFeed :one
-INSERT INTO feeds (id, created_at, updated_at, name, url, user_id)
+INSERT INTO feeds (id, created_at, updated_at, name, url, user_id, normalized_url)
 VALUES (
     $1,
     $2,
     $3,
     $4,
     $5,
-    $6
+    $6,
+    $7
 )
-RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, lease_owner, lease_expires_at, title, site_url, description, language, image_url, generator, normalized_url
 `
 
 type CreateFeedParams struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	Name      string
-	Url       string
-	UserID    uuid.UUID
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Name          string
+	Url           string
+	UserID        uuid.UUID
+	NormalizedUrl string
 }
 
 func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, error) {
@@ -42,7 +91,54 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		arg.Name,
 		arg.Url,
 		arg.UserID,
+		arg.NormalizedUrl,
+	)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.LeaseOwner,
+		&i.LeaseExpiresAt,
+		&i.Title,
+		&i.SiteUrl,
+		&i.Description,
+		&i.Language,
+		&i.ImageUrl,
+		&i.Generator,
+		&i.NormalizedUrl,
 	)
+	return i, err
+}
+
+const deleteFeed = `-- name: DeleteFeed :exec
+DELETE FROM feeds WHERE id = $1
+`
+
+func (q *Queries) DeleteFeed(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteFeed, id)
+	return err
+}
+
+const deleteFeeds = `-- name: DeleteFeeds :exec
+DELETE FROM feeds
+`
+
+func (q *Queries) DeleteFeeds(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteFeeds)
+	return err
+}
+
+const getFeedByID = `-- name: GetFeedByID :one
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, lease_owner, lease_expires_at, title, site_url, description, language, image_url, generator, normalized_url FROM feeds WHERE id = $1
+`
+
+func (q *Queries) GetFeedByID(ctx context.Context, id uuid.UUID) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, getFeedByID, id)
 	var i Feed
 	err := row.Scan(
 		&i.ID,
@@ -52,16 +148,35 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.LeaseOwner,
+		&i.LeaseExpiresAt,
+		&i.Title,
+		&i.SiteUrl,
+		&i.Description,
+		&i.Language,
+		&i.ImageUrl,
+		&i.Generator,
+		&i.NormalizedUrl,
 	)
 	return i, err
 }
 
 const getFeedByUrl = `-- name: GetFeedByUrl :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds WHERE url = $1
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, lease_owner, lease_expires_at, title, site_url, description, language, image_url, generator, normalized_url FROM feeds
+WHERE url = $1 OR normalized_url = $2
+ORDER BY normalized_url = $2 DESC
+LIMIT 1
 `
 
-func (q *Queries) GetFeedByUrl(ctx context.Context, url string) (Feed, error) {
-	row := q.db.QueryRowContext(ctx, getFeedByUrl, url)
+type GetFeedByUrlParams struct {
+	Url           string
+	NormalizedUrl string
+}
+
+// Matches the URL as given or normalized, so feeds added before URLs
+// were normalized are still found
+func (q *Queries) GetFeedByUrl(ctx context.Context, arg GetFeedByUrlParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, getFeedByUrl, arg.Url, arg.NormalizedUrl)
 	var i Feed
 	err := row.Scan(
 		&i.ID,
@@ -71,10 +186,98 @@ func (q *Queries) GetFeedByUrl(ctx context.Context, url string) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.LeaseOwner,
+		&i.LeaseExpiresAt,
+		&i.Title,
+		&i.SiteUrl,
+		&i.Description,
+		&i.Language,
+		&i.ImageUrl,
+		&i.Generator,
+		&i.NormalizedUrl,
 	)
 	return i, err
 }
 
+const getFeedUrls = `-- name: GetFeedUrls :many
+SELECT id, url, normalized_url FROM feeds ORDER BY created_at
+`
+
+type GetFeedUrlsRow struct {
+	ID            uuid.UUID
+	Url           string
+	NormalizedUrl string
+}
+
+// Every feed URL with its normalized form, oldest first
+func (q *Queries) GetFeedUrls(ctx context.Context) ([]GetFeedUrlsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedUrls)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFeedUrlsRow
+	for rows.Next() {
+		var i GetFeedUrlsRow
+		if err := rows.Scan(&i.ID, &i.Url, &i.NormalizedUrl); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFeeds = `-- name: GetFeeds :many
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, lease_owner, lease_expires_at, title, site_url, description, language, image_url, generator, normalized_url FROM feeds
+ORDER BY name
+`
+
+func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, getFeeds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.LeaseOwner,
+			&i.LeaseExpiresAt,
+			&i.Title,
+			&i.SiteUrl,
+			&i.Description,
+			&i.Language,
+			&i.ImageUrl,
+			&i.Generator,
+			&i.NormalizedUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getFeedsWithUserNames = `-- name: GetFeedsWithUserNames :many
 SELECT 
     f.name AS feed_name,
@@ -115,27 +318,6 @@ func (q *Queries) GetFeedsWithUserNames(ctx context.Context) ([]GetFeedsWithUser
 	return items, nil
 }
 
-const getNextFeedToFetch = `-- name: GetNextFeedToFetch :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
-ORDER BY last_fetched_at NULLS FIRST, updated_at ASC
-LIMIT 1
-`
-
-func (q *Queries) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
-	row := q.db.QueryRowContext(ctx, getNextFeedToFetch)
-	var i Feed
-	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.Name,
-		&i.Url,
-		&i.UserID,
-		&i.LastFetchedAt,
-	)
-	return i, err
-}
-
 const markFeedFetched = `-- name: MarkFeedFetched :exec
 UPDATE feeds 
 SET last_fetched_at = NOW(),
@@ -147,3 +329,69 @@ func (q *Queries) MarkFeedFetched(ctx context.Context, id uuid.UUID) error {
 	_, err := q.db.ExecContext(ctx, markFeedFetched, id)
 	return err
 }
+
+const releaseFeedLease = `-- name: ReleaseFeedLease :exec
+UPDATE feeds
+SET lease_owner = NULL,
+    lease_expires_at = NULL
+WHERE id = $1 AND lease_owner = $2
+`
+
+type ReleaseFeedLeaseParams struct {
+	ID         uuid.UUID
+	LeaseOwner sql.NullString
+}
+
+func (q *Queries) ReleaseFeedLease(ctx context.Context, arg ReleaseFeedLeaseParams) error {
+	_, err := q.db.ExecContext(ctx, releaseFeedLease, arg.ID, arg.LeaseOwner)
+	return err
+}
+
+const setFeedNormalizedUrl = `-- name: SetFeedNormalizedUrl :exec
+UPDATE feeds SET normalized_url = $2 WHERE id = $1
+`
+
+type SetFeedNormalizedUrlParams struct {
+	ID            uuid.UUID
+	NormalizedUrl string
+}
+
+func (q *Queries) SetFeedNormalizedUrl(ctx context.Context, arg SetFeedNormalizedUrlParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedNormalizedUrl, arg.ID, arg.NormalizedUrl)
+	return err
+}
+
+const updateFeedMetadata = `-- name: UpdateFeedMetadata :exec
+UPDATE feeds
+SET title = $2,
+    site_url = $3,
+    description = $4,
+    language = $5,
+    image_url = $6,
+    generator = $7
+WHERE id = $1
+`
+
+type UpdateFeedMetadataParams struct {
+	ID          uuid.UUID
+	Title       sql.NullString
+	SiteUrl     sql.NullString
+	Description sql.NullString
+	Language    sql.NullString
+	ImageUrl    sql.NullString
+	Generator   sql.NullString
+}
+
+// Refreshes what the feed says about itself on every fetch
+func (q *Queries) UpdateFeedMetadata(ctx context.Context, arg UpdateFeedMetadataParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedMetadata,
+		arg.ID,
+		arg.Title,
+		arg.SiteUrl,
+		arg.Description,
+		arg.Language,
+		arg.ImageUrl,
+		arg.Generator,
+	)
+	return err
+}
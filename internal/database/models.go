@@ -11,14 +11,50 @@ import (
 	"github.com/google/uuid"
 )
 
+type ApiToken struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UserID     uuid.UUID
+	Name       string
+	TokenHash  string
+	Scopes     string
+	ExpiresAt  sql.NullTime
+	LastUsedAt sql.NullTime
+}
+
+type Category struct {
+	ID   uuid.UUID
+	Name string
+}
+
+type DigestSubscription struct {
+	UserID         uuid.UUID
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	Email          string
+	Frequency      string
+	LastSentAt     sql.NullTime
+	LeaseOwner     sql.NullString
+	LeaseExpiresAt sql.NullTime
+}
+
 type Feed struct {
-	ID            uuid.UUID
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	Name          string
-	Url           string
-	UserID        uuid.UUID
-	LastFetchedAt sql.NullTime
+	ID             uuid.UUID
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	Name           string
+	Url            string
+	UserID         uuid.UUID
+	LastFetchedAt  sql.NullTime
+	LeaseOwner     sql.NullString
+	LeaseExpiresAt sql.NullTime
+	Title          sql.NullString
+	SiteUrl        sql.NullString
+	Description    sql.NullString
+	Language       sql.NullString
+	ImageUrl       sql.NullString
+	Generator      sql.NullString
+	NormalizedUrl  string
 }
 
 type FeedFollow struct {
@@ -29,20 +65,103 @@ type FeedFollow struct {
 	FeedID    uuid.UUID
 }
 
+type FeedHttpSetting struct {
+	FeedID           uuid.UUID
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	EncryptedOptions string
+}
+
 type Post struct {
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Title         string
+	Url           string
+	Description   sql.NullString
+	PublishedAt   sql.NullTime
+	FeedID        uuid.UUID
+	Author        sql.NullString
+	CanonicalUrl  sql.NullString
+	StoryID       uuid.NullUUID
+	NormalizedUrl string
+}
+
+type PostCategory struct {
+	PostID     uuid.UUID
+	CategoryID uuid.UUID
+}
+
+type PostFeed struct {
+	PostID uuid.UUID
+	FeedID uuid.UUID
+	SeenAt time.Time
+}
+
+type PostRevision struct {
 	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	PostID      uuid.UUID
+	ReplacedAt  time.Time
 	Title       string
-	Url         string
 	Description sql.NullString
-	PublishedAt sql.NullTime
-	FeedID      uuid.UUID
 }
 
-type User struct {
+type Session struct {
 	ID        uuid.UUID
 	CreatedAt time.Time
-	UpdatedAt time.Time
-	Name      string
+	ExpiresAt time.Time
+	TokenHash string
+	UserID    uuid.UUID
+}
+
+type User struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Name         string
+	PasswordHash sql.NullString
+	Role         string
+}
+
+type UserSetting struct {
+	UserID      uuid.UUID
+	UpdatedAt   time.Time
+	Timezone    sql.NullString
+	DateFormat  sql.NullString
+	BrowseLimit sql.NullInt32
+	SortOrder   sql.NullString
+	Language    sql.NullString
+}
+
+type Webhook struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	UserID       uuid.UUID
+	Url          string
+	Secret       string
+	FeedID       uuid.NullUUID
+	MatchKeyword sql.NullString
+}
+
+type WebhookDelivery struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	WebhookID  uuid.UUID
+	PostID     uuid.UUID
+	Attempts   int32
+	StatusCode sql.NullInt32
+	Error      sql.NullString
+	Succeeded  bool
+}
+
+type WebsubSubscription struct {
+	FeedID         uuid.UUID
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	HubUrl         string
+	TopicUrl       string
+	Secret         string
+	VerifiedAt     sql.NullTime
+	LeaseExpiresAt sql.NullTime
 }
@@ -14,20 +14,24 @@ import (
 )
 
 const createPost = `-- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, author, canonical_url, story_id, normalized_url)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id, author, canonical_url, story_id, normalized_url
 `
 
 type CreatePostParams struct {
-	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       string
-	Url         string
-	Description sql.NullString
-	PublishedAt sql.NullTime
-	FeedID      uuid.UUID
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Title         string
+	Url           string
+	Description   sql.NullString
+	PublishedAt   sql.NullTime
+	FeedID        uuid.UUID
+	Author        sql.NullString
+	CanonicalUrl  sql.NullString
+	StoryID       uuid.NullUUID
+	NormalizedUrl string
 }
 
 func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, error) {
@@ -40,6 +44,10 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		arg.Description,
 		arg.PublishedAt,
 		arg.FeedID,
+		arg.Author,
+		arg.CanonicalUrl,
+		arg.StoryID,
+		arg.NormalizedUrl,
 	)
 	var i Post
 	err := row.Scan(
@@ -51,10 +59,114 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		&i.Description,
 		&i.PublishedAt,
 		&i.FeedID,
+		&i.Author,
+		&i.CanonicalUrl,
+		&i.StoryID,
+		&i.NormalizedUrl,
 	)
 	return i, err
 }
 
+const deletePosts = `-- name: DeletePosts :exec
+DELETE FROM posts
+`
+
+func (q *Queries) DeletePosts(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deletePosts)
+	return err
+}
+
+const getPostByID = `-- name: GetPostByID :one
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, author, canonical_url, story_id, normalized_url FROM posts WHERE id = $1
+`
+
+func (q *Queries) GetPostByID(ctx context.Context, id uuid.UUID) (Post, error) {
+	row := q.db.QueryRowContext(ctx, getPostByID, id)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.Author,
+		&i.CanonicalUrl,
+		&i.StoryID,
+		&i.NormalizedUrl,
+	)
+	return i, err
+}
+
+const getPostByUrl = `-- name: GetPostByUrl :one
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, author, canonical_url, story_id, normalized_url FROM posts
+WHERE url = $1 OR normalized_url = $2
+ORDER BY normalized_url = $2 DESC
+LIMIT 1
+`
+
+type GetPostByUrlParams struct {
+	Url           string
+	NormalizedUrl string
+}
+
+// Matches the URL as given or normalized, like GetFeedByUrl
+func (q *Queries) GetPostByUrl(ctx context.Context, arg GetPostByUrlParams) (Post, error) {
+	row := q.db.QueryRowContext(ctx, getPostByUrl, arg.Url, arg.NormalizedUrl)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.Author,
+		&i.CanonicalUrl,
+		&i.StoryID,
+		&i.NormalizedUrl,
+	)
+	return i, err
+}
+
+const getPostUrls = `-- name: GetPostUrls :many
+SELECT id, url, normalized_url FROM posts ORDER BY created_at
+`
+
+type GetPostUrlsRow struct {
+	ID            uuid.UUID
+	Url           string
+	NormalizedUrl string
+}
+
+// Every post URL with its normalized form, oldest first
+func (q *Queries) GetPostUrls(ctx context.Context) ([]GetPostUrlsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostUrls)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostUrlsRow
+	for rows.Next() {
+		var i GetPostUrlsRow
+		if err := rows.Scan(&i.ID, &i.Url, &i.NormalizedUrl); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getPostsByUser = `-- name: GetPostsByUser :many
 SELECT 
     p.id,
@@ -65,34 +177,62 @@ SELECT
     p.description,
     p.published_at,
     p.feed_id,
-    f.name AS feed_name
+    p.author,
+    p.story_id,
+    f.name AS feed_name,
+    (SELECT COUNT(*) FROM post_revisions r WHERE r.post_id = p.id) AS revision_count
 FROM posts p
 JOIN feeds f ON p.feed_id = f.id
 JOIN feed_follows ff ON f.id = ff.feed_id
 WHERE ff.user_id = $1
-ORDER BY p.published_at DESC
-LIMIT $2
+  AND ($2::text IS NULL OR EXISTS (
+    SELECT 1 FROM post_categories pc
+    JOIN categories c ON c.id = pc.category_id
+    WHERE pc.post_id = p.id AND lower(c.name) = lower($2)
+  ))
+  AND ($3::text IS NULL OR p.author ILIKE '%' || $3 || '%')
+  AND NOT EXISTS (
+    -- Show each story once, as the first post seen in a followed feed
+    SELECT 1 FROM posts other
+    JOIN feed_follows other_follow ON other_follow.feed_id = other.feed_id AND other_follow.user_id = ff.user_id
+    WHERE other.story_id = p.story_id
+      AND (other.created_at < p.created_at OR (other.created_at = p.created_at AND other.id < p.id))
+  )
+ORDER BY CASE WHEN $4::boolean THEN p.published_at END ASC, p.published_at DESC
+LIMIT $5
 `
 
 type GetPostsByUserParams struct {
-	UserID uuid.UUID
-	Limit  int32
+	UserID      uuid.UUID
+	Category    sql.NullString
+	Author      sql.NullString
+	OldestFirst bool
+	Limit       int32
 }
 
 type GetPostsByUserRow struct {
-	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       string
-	Url         string
-	Description sql.NullString
-	PublishedAt sql.NullTime
-	FeedID      uuid.UUID
-	FeedName    string
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Title         string
+	Url           string
+	Description   sql.NullString
+	PublishedAt   sql.NullTime
+	FeedID        uuid.UUID
+	Author        sql.NullString
+	StoryID       uuid.NullUUID
+	FeedName      string
+	RevisionCount int64
 }
 
 func (q *Queries) GetPostsByUser(ctx context.Context, arg GetPostsByUserParams) ([]GetPostsByUserRow, error) {
-	rows, err := q.db.QueryContext(ctx, getPostsByUser, arg.UserID, arg.Limit)
+	rows, err := q.db.QueryContext(ctx, getPostsByUser,
+		arg.UserID,
+		arg.Category,
+		arg.Author,
+		arg.OldestFirst,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -109,7 +249,10 @@ func (q *Queries) GetPostsByUser(ctx context.Context, arg GetPostsByUserParams)
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.Author,
+			&i.StoryID,
 			&i.FeedName,
+			&i.RevisionCount,
 		); err != nil {
 			return nil, err
 		}
@@ -123,3 +266,164 @@ func (q *Queries) GetPostsByUser(ctx context.Context, arg GetPostsByUserParams)
 	}
 	return items, nil
 }
+
+const searchPostsByUser = `-- name: SearchPostsByUser :many
+SELECT 
+    p.id,
+    p.created_at,
+    p.updated_at,
+    p.title,
+    p.url,
+    p.description,
+    p.published_at,
+    p.feed_id,
+    p.author,
+    p.story_id,
+    f.name AS feed_name,
+    (SELECT COUNT(*) FROM post_revisions r WHERE r.post_id = p.id) AS revision_count
+FROM posts p
+JOIN feeds f ON p.feed_id = f.id
+JOIN feed_follows ff ON f.id = ff.feed_id
+WHERE ff.user_id = $1
+  AND (p.title ILIKE '%' || $2::text || '%' OR p.description ILIKE '%' || $2::text || '%')
+  AND ($3::text IS NULL OR EXISTS (
+    SELECT 1 FROM post_categories pc
+    JOIN categories c ON c.id = pc.category_id
+    WHERE pc.post_id = p.id AND lower(c.name) = lower($3)
+  ))
+  AND ($4::text IS NULL OR p.author ILIKE '%' || $4 || '%')
+  AND NOT EXISTS (
+    -- Show each story once, as the first post seen in a followed feed
+    SELECT 1 FROM posts other
+    JOIN feed_follows other_follow ON other_follow.feed_id = other.feed_id AND other_follow.user_id = ff.user_id
+    WHERE other.story_id = p.story_id
+      AND (other.created_at < p.created_at OR (other.created_at = p.created_at AND other.id < p.id))
+  )
+ORDER BY CASE WHEN $5::boolean THEN p.published_at END ASC, p.published_at DESC
+LIMIT $6
+`
+
+type SearchPostsByUserParams struct {
+	UserID      uuid.UUID
+	Query       string
+	Category    sql.NullString
+	Author      sql.NullString
+	OldestFirst bool
+	Limit       int32
+}
+
+type SearchPostsByUserRow struct {
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Title         string
+	Url           string
+	Description   sql.NullString
+	PublishedAt   sql.NullTime
+	FeedID        uuid.UUID
+	Author        sql.NullString
+	StoryID       uuid.NullUUID
+	FeedName      string
+	RevisionCount int64
+}
+
+// Matches the text in titles and descriptions, ignoring case
+func (q *Queries) SearchPostsByUser(ctx context.Context, arg SearchPostsByUserParams) ([]SearchPostsByUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchPostsByUser,
+		arg.UserID,
+		arg.Query,
+		arg.Category,
+		arg.Author,
+		arg.OldestFirst,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchPostsByUserRow
+	for rows.Next() {
+		var i SearchPostsByUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Author,
+			&i.StoryID,
+			&i.FeedName,
+			&i.RevisionCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setPostNormalizedUrl = `-- name: SetPostNormalizedUrl :exec
+UPDATE posts SET normalized_url = $2 WHERE id = $1
+`
+
+type SetPostNormalizedUrlParams struct {
+	ID            uuid.UUID
+	NormalizedUrl string
+}
+
+func (q *Queries) SetPostNormalizedUrl(ctx context.Context, arg SetPostNormalizedUrlParams) error {
+	_, err := q.db.ExecContext(ctx, setPostNormalizedUrl, arg.ID, arg.NormalizedUrl)
+	return err
+}
+
+const updatePostCanonical = `-- name: UpdatePostCanonical :exec
+UPDATE posts
+SET canonical_url = $2,
+    story_id = $3
+WHERE id = $1
+`
+
+type UpdatePostCanonicalParams struct {
+	ID           uuid.UUID
+	CanonicalUrl sql.NullString
+	StoryID      uuid.NullUUID
+}
+
+func (q *Queries) UpdatePostCanonical(ctx context.Context, arg UpdatePostCanonicalParams) error {
+	_, err := q.db.ExecContext(ctx, updatePostCanonical, arg.ID, arg.CanonicalUrl, arg.StoryID)
+	return err
+}
+
+const updatePostContent = `-- name: UpdatePostContent :exec
+UPDATE posts
+SET title = $2,
+    description = $3,
+    updated_at = $4
+WHERE id = $1
+`
+
+type UpdatePostContentParams struct {
+	ID          uuid.UUID
+	Title       string
+	Description sql.NullString
+	UpdatedAt   time.Time
+}
+
+func (q *Queries) UpdatePostContent(ctx context.Context, arg UpdatePostContentParams) error {
+	_, err := q.db.ExecContext(ctx, updatePostContent,
+		arg.ID,
+		arg.Title,
+		arg.Description,
+		arg.UpdatedAt,
+	)
+	return err
+}
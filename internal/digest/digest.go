@@ -0,0 +1,288 @@
+package digest
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	htmltemplate "html/template"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/twomotive/GoFlux/internal/config"
+)
+
+const (
+	// defaultSMTPPort is used when the config does not set a port
+	defaultSMTPPort = 25
+	// sendTimeout bounds delivering one digest, from dialing to QUIT
+	sendTimeout = time.Minute
+)
+
+// Post is a single entry listed in a digest
+type Post struct {
+	Title       string
+	URL         string
+	Description string
+	PublishedAt *time.Time
+}
+
+// Section groups the posts of one feed
+type Section struct {
+	Feed  string
+	Posts []Post
+}
+
+// Digest is a rendered summary ready to be mailed
+type Digest struct {
+	Subject  string
+	Text     string
+	HTML     string
+	Language string // Sent as Content-Language when set
+}
+
+// defaultDateLayout formats dates of readers without a preference
+const defaultDateLayout = "Mon, 02 Jan 2006 15:04 MST"
+
+// Options are the reader's preferences for rendering a digest
+type Options struct {
+	Location   *time.Location // Dates are shown in UTC when nil
+	DateLayout string
+	Language   string
+}
+
+// templateData is what both body templates are rendered with
+type templateData struct {
+	UserName   string
+	Since      time.Time
+	PostCount  int
+	Sections   []Section
+	DateLayout string
+}
+
+var textTemplate = template.Must(template.New("text").Parse(`Hello {{.UserName}},
+
+Here are {{.PostCount}} new posts since {{.Since.Format .DateLayout}}.
+{{range .Sections}}
+== {{.Feed}} ==
+{{range .Posts}}
+* {{.Title}}
+  {{.URL}}
+{{- if .PublishedAt}}
+  Published: {{.PublishedAt.Format $.DateLayout}}
+{{- end}}
+{{end}}{{end}}
+--
+GoFlux
+`))
+
+var htmlTemplate = htmltemplate.Must(htmltemplate.New("html").Parse(`<!DOCTYPE html>
+<html>
+<body>
+<p>Hello {{.UserName}},</p>
+<p>Here are {{.PostCount}} new posts since {{.Since.Format .DateLayout}}.</p>
+{{range .Sections}}
+<h2>{{.Feed}}</h2>
+<ul>
+{{range .Posts}}<li>
+<a href="{{.URL}}">{{.Title}}</a>
+{{if .PublishedAt}}<br><small>{{.PublishedAt.Format $.DateLayout}}</small>{{end}}
+{{if .Description}}<p>{{.Description}}</p>{{end}}
+</li>
+{{end}}</ul>
+{{end}}
+<p>-- GoFlux</p>
+</body>
+</html>
+`))
+
+// Build renders the plain-text and HTML versions of a digest, with
+// dates in the reader's timezone and format
+func Build(userName string, since time.Time, sections []Section, opts Options) (*Digest, error) {
+	location := opts.Location
+	if location == nil {
+		location = time.UTC
+	}
+	data := templateData{
+		UserName:   userName,
+		Since:      since.In(location),
+		DateLayout: opts.DateLayout,
+	}
+	if data.DateLayout == "" {
+		data.DateLayout = defaultDateLayout
+	}
+	for _, section := range sections {
+		localized := Section{Feed: section.Feed}
+		for _, post := range section.Posts {
+			if post.PublishedAt != nil {
+				published := post.PublishedAt.In(location)
+				post.PublishedAt = &published
+			}
+			localized.Posts = append(localized.Posts, post)
+		}
+		data.Sections = append(data.Sections, localized)
+		data.PostCount += len(section.Posts)
+	}
+
+	var text bytes.Buffer
+	if err := textTemplate.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("cannot render text digest: %v", err)
+	}
+
+	var html bytes.Buffer
+	if err := htmlTemplate.Execute(&html, data); err != nil {
+		return nil, fmt.Errorf("cannot render HTML digest: %v", err)
+	}
+
+	return &Digest{
+		Subject:  fmt.Sprintf("GoFlux digest: %d new posts", data.PostCount),
+		Text:     text.String(),
+		HTML:     html.String(),
+		Language: opts.Language,
+	}, nil
+}
+
+// Message builds a multipart/alternative MIME message for the digest
+func Message(from, to string, d *Digest) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", to)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", d.Subject)
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	if d.Language != "" {
+		fmt.Fprintf(&buf, "Content-Language: %s\r\n", d.Language)
+	}
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", writer.Boundary())
+
+	parts := []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain; charset=utf-8", d.Text},
+		{"text/html; charset=utf-8", d.HTML},
+	}
+
+	for _, part := range parts {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", part.contentType)
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+
+		w, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create MIME part: %v", err)
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.body)); err != nil {
+			return nil, fmt.Errorf("cannot encode MIME part: %v", err)
+		}
+		if err := qp.Close(); err != nil {
+			return nil, fmt.Errorf("cannot encode MIME part: %v", err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("cannot finish MIME message: %v", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Send delivers the digest to a single recipient over SMTP. The whole
+// exchange is bounded by sendTimeout and stops when ctx is cancelled
+func Send(ctx context.Context, cfg config.SMTPConfig, to string, d *Digest) error {
+	if cfg.Host == "" {
+		return fmt.Errorf("smtp host is not configured")
+	}
+	if cfg.From == "" {
+		return fmt.Errorf("smtp sender address is not configured")
+	}
+
+	port := cfg.Port
+	if port == 0 {
+		port = defaultSMTPPort
+	}
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(port))
+
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+
+	msg, err := Message(cfg.From, to, d)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
+	if err := sendMail(ctx, addr, cfg.Host, auth, cfg.From, to, msg); err != nil {
+		return fmt.Errorf("cannot send mail via %s: %w", addr, err)
+	}
+
+	return nil
+}
+
+// sendMail is smtp.SendMail over a connection that gives up at ctx's
+// deadline or cancellation instead of waiting on a stalled server
+func sendMail(ctx context.Context, addr, host string, auth smtp.Auth, from, to string, msg []byte) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if auth != nil {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return fmt.Errorf("smtp server doesn't support AUTH")
+		}
+		if err := c.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(from); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
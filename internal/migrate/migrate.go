@@ -0,0 +1,347 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// versionTable is the table goose uses to record applied migrations,
+// so databases migrated with the goose CLI are recognized
+const versionTable = "goose_db_version"
+
+// Dialect selects the SQL flavour used for the version table
+type Dialect int
+
+const (
+	Postgres Dialect = iota
+	SQLite
+)
+
+// Migration is a single goose-style SQL migration
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status describes whether a migration has been applied
+type Status struct {
+	Migration Migration
+	AppliedAt sql.NullTime
+}
+
+// Step prepares data that a SQL migration cannot, such as values
+// computed in Go. It must be safe to run again after a failure
+type Step func(ctx context.Context) error
+
+// Migrator applies embedded migrations to a database
+type Migrator struct {
+	db         *sql.DB
+	dialect    Dialect
+	migrations []Migration
+	steps      map[int64]Step
+}
+
+// New loads the migrations found in fsys and prepares a Migrator
+func New(db *sql.DB, fsys fs.FS, dialect Dialect) (*Migrator, error) {
+	migrations, err := load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, dialect: dialect, migrations: migrations, steps: make(map[int64]Step)}, nil
+}
+
+// BeforeUp runs step every time migration version is about to be
+// applied. A failing step stops the migration before its SQL runs, so
+// the next Up tries the step again
+func (m *Migrator) BeforeUp(version int64, step Step) {
+	m.steps[version] = step
+}
+
+// load reads "<version>_<name>.sql" files and splits them on the
+// "-- +goose Up" and "-- +goose Down" annotations
+func load(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("cannot list migrations: %v", err)
+	}
+
+	var migrations []Migration
+	seen := make(map[int64]string)
+	for _, file := range files {
+		prefix, _, ok := strings.Cut(file, "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %s has no version prefix", file)
+		}
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s has an invalid version: %v", file, err)
+		}
+		if other, dup := seen[version]; dup {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", other, file, version)
+		}
+		seen[version] = file
+
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read migration %s: %v", file, err)
+		}
+
+		up, down := split(string(content))
+		migrations = append(migrations, Migration{
+			Version: version,
+			Name:    strings.TrimSuffix(path.Base(file), ".sql"),
+			Up:      up,
+			Down:    down,
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+func split(content string) (up, down string) {
+	var current *strings.Builder
+	var upBuf, downBuf strings.Builder
+
+	for _, line := range strings.SplitAfter(content, "\n") {
+		switch strings.TrimSpace(line) {
+		case "-- +goose Up":
+			current = &upBuf
+			continue
+		case "-- +goose Down":
+			current = &downBuf
+			continue
+		}
+		if current != nil {
+			current.WriteString(line)
+		}
+	}
+
+	return strings.TrimSpace(upBuf.String()), strings.TrimSpace(downBuf.String())
+}
+
+// Latest returns the highest version known to this binary
+func (m *Migrator) Latest() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// ensureVersionTable creates the goose version table when it is missing
+func (m *Migrator) ensureVersionTable(ctx context.Context) error {
+	idColumn := "id SERIAL PRIMARY KEY"
+	if m.dialect == SQLite {
+		idColumn = "id INTEGER PRIMARY KEY AUTOINCREMENT"
+	}
+
+	_, err := m.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+versionTable+` (
+    `+idColumn+`,
+    version_id BIGINT NOT NULL,
+    is_applied BOOLEAN NOT NULL,
+    tstamp TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+)`)
+	if err != nil {
+		return fmt.Errorf("cannot create version table: %v", err)
+	}
+	return nil
+}
+
+// Current returns the version the database is migrated to, 0 when none.
+// A database without a version table is reported as version 0
+func (m *Migrator) Current(ctx context.Context) (int64, error) {
+	existsQuery := `SELECT to_regclass($1) IS NOT NULL`
+	if m.dialect == SQLite {
+		existsQuery = `SELECT COUNT(*) > 0 FROM sqlite_master WHERE type = 'table' AND name = $1`
+	}
+
+	var exists bool
+	err := m.db.QueryRowContext(ctx, existsQuery, versionTable).Scan(&exists)
+	if err != nil {
+		return 0, fmt.Errorf("cannot check schema version: %v", err)
+	}
+	if !exists {
+		return 0, nil
+	}
+
+	rows, err := m.db.QueryContext(ctx, `SELECT version_id, is_applied FROM `+versionTable+` ORDER BY id DESC`)
+	if err != nil {
+		return 0, fmt.Errorf("cannot read schema version: %v", err)
+	}
+	defer rows.Close()
+
+	// Like goose, the newest row of a version decides whether it is applied
+	rolledBack := make(map[int64]bool)
+	for rows.Next() {
+		var version int64
+		var applied bool
+		if err := rows.Scan(&version, &applied); err != nil {
+			return 0, fmt.Errorf("cannot read schema version: %v", err)
+		}
+		if rolledBack[version] {
+			continue
+		}
+		if applied {
+			return version, nil
+		}
+		rolledBack[version] = true
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("cannot read schema version: %v", err)
+	}
+
+	return 0, nil
+}
+
+// Up applies every pending migration and returns the ones it ran
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	if err := m.ensureVersionTable(ctx); err != nil {
+		return nil, err
+	}
+
+	current, err := m.Current(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	for _, migration := range m.migrations {
+		if migration.Version <= current {
+			continue
+		}
+		if err := m.up(ctx, migration); err != nil {
+			return applied, err
+		}
+		applied = append(applied, migration)
+	}
+
+	return applied, nil
+}
+
+// Down rolls back the most recently applied migration
+func (m *Migrator) Down(ctx context.Context) (*Migration, error) {
+	current, err := m.Current(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if current == 0 {
+		return nil, fmt.Errorf("no migrations to roll back")
+	}
+
+	migration, ok := m.find(current)
+	if !ok {
+		return nil, fmt.Errorf("database is at version %d which this binary does not know", current)
+	}
+
+	if err := m.run(ctx, migration.Version, migration.Down, false); err != nil {
+		return nil, fmt.Errorf("rollback of %s failed: %v", migration.Name, err)
+	}
+
+	return &migration, nil
+}
+
+// Redo rolls back the most recent migration and applies it again
+func (m *Migrator) Redo(ctx context.Context) (*Migration, error) {
+	migration, err := m.Down(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.up(ctx, *migration); err != nil {
+		return nil, err
+	}
+
+	return migration, nil
+}
+
+// Status reports every known migration with its applied time, if any
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	if err := m.ensureVersionTable(ctx); err != nil {
+		return nil, err
+	}
+
+	current, err := m.Current(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Migration: migration}
+		if migration.Version <= current {
+			err := m.db.QueryRowContext(ctx,
+				`SELECT tstamp FROM `+versionTable+` WHERE version_id = $1 AND is_applied ORDER BY id DESC LIMIT 1`,
+				migration.Version,
+			).Scan(&status.AppliedAt)
+			if err != nil && err != sql.ErrNoRows {
+				return nil, fmt.Errorf("cannot read status of %s: %v", migration.Name, err)
+			}
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// up runs the step registered for a migration, then applies it
+func (m *Migrator) up(ctx context.Context, migration Migration) error {
+	if step, ok := m.steps[migration.Version]; ok {
+		if err := step(ctx); err != nil {
+			return fmt.Errorf("preparing migration %s failed: %v", migration.Name, err)
+		}
+	}
+	if err := m.run(ctx, migration.Version, migration.Up, true); err != nil {
+		return fmt.Errorf("migration %s failed: %v", migration.Name, err)
+	}
+	return nil
+}
+
+// run executes one direction of a migration and records it, atomically
+func (m *Migrator) run(ctx context.Context, version int64, statements string, up bool) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if statements != "" {
+		if _, err := tx.ExecContext(ctx, statements); err != nil {
+			return err
+		}
+	}
+
+	if up {
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO `+versionTable+` (version_id, is_applied, tstamp) VALUES ($1, TRUE, $2)`,
+			version, time.Now().UTC(),
+		)
+	} else {
+		_, err = tx.ExecContext(ctx, `DELETE FROM `+versionTable+` WHERE version_id = $1`, version)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot record version: %v", err)
+	}
+
+	return tx.Commit()
+}
+
+func (m *Migrator) find(version int64) (Migration, bool) {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
@@ -0,0 +1,183 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		up, down string
+	}{
+		{
+			name: "up and down",
+			content: `-- +goose Up
+CREATE TABLE a (id INTEGER);
+CREATE TABLE b (id INTEGER);
+
+-- +goose Down
+DROP TABLE b;
+DROP TABLE a;
+`,
+			up:   "CREATE TABLE a (id INTEGER);\nCREATE TABLE b (id INTEGER);",
+			down: "DROP TABLE b;\nDROP TABLE a;",
+		},
+		{
+			name:    "text before the annotations is ignored",
+			content: "-- A comment\n-- +goose Up\nSELECT 1;\n",
+			up:      "SELECT 1;",
+		},
+		{
+			name:    "indented annotations",
+			content: "  -- +goose Down  \nSELECT 2;\n  -- +goose Up\nSELECT 1;\n",
+			up:      "SELECT 1;",
+			down:    "SELECT 2;",
+		},
+		{
+			name:    "no annotations",
+			content: "SELECT 1;\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			up, down := split(tt.content)
+			if up != tt.up {
+				t.Errorf("up = %q, want %q", up, tt.up)
+			}
+			if down != tt.down {
+				t.Errorf("down = %q, want %q", down, tt.down)
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+		want string
+	}{
+		{
+			name: "missing version",
+			fsys: fstest.MapFS{"users.sql": {}},
+			want: "migration users.sql has no version prefix",
+		},
+		{
+			name: "invalid version",
+			fsys: fstest.MapFS{"one_users.sql": {}},
+			want: "migration one_users.sql has an invalid version",
+		},
+		{
+			name: "shared version",
+			fsys: fstest.MapFS{"001_users.sql": {}, "1_feeds.sql": {}},
+			want: "share version 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := load(tt.fsys)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("load error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrator(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	// Every connection to :memory: is a new database
+	db.SetMaxOpenConns(1)
+
+	fsys := fstest.MapFS{
+		"002_feeds.sql": {Data: []byte("-- +goose Up\nCREATE TABLE feeds (id INTEGER);\n-- +goose Down\nDROP TABLE feeds;\n")},
+		"001_users.sql": {Data: []byte("-- +goose Up\nCREATE TABLE users (id INTEGER);\n-- +goose Down\nDROP TABLE users;\n")},
+	}
+	m, err := New(db, fsys, SQLite)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	if m.Latest() != 2 {
+		t.Errorf("Latest() = %d, want 2", m.Latest())
+	}
+
+	current := func() int64 {
+		t.Helper()
+		version, err := m.Current(ctx)
+		if err != nil {
+			t.Fatalf("Current returned error: %v", err)
+		}
+		return version
+	}
+
+	if got := current(); got != 0 {
+		t.Errorf("Current() without a version table = %d, want 0", got)
+	}
+
+	var stepRuns int
+	m.BeforeUp(2, func(ctx context.Context) error {
+		stepRuns++
+		return nil
+	})
+
+	applied, err := m.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up returned error: %v", err)
+	}
+	if len(applied) != 2 || applied[0].Name != "001_users" || applied[1].Name != "002_feeds" {
+		t.Errorf("Up applied %v, want 001_users and 002_feeds in order", applied)
+	}
+	if got := current(); got != 2 {
+		t.Errorf("Current() after Up = %d, want 2", got)
+	}
+	if stepRuns != 1 {
+		t.Errorf("step ran %d times, want 1", stepRuns)
+	}
+
+	rolledBack, err := m.Down(ctx)
+	if err != nil {
+		t.Fatalf("Down returned error: %v", err)
+	}
+	if rolledBack.Version != 2 {
+		t.Errorf("Down rolled back version %d, want 2", rolledBack.Version)
+	}
+	if got := current(); got != 1 {
+		t.Errorf("Current() after Down = %d, want 1", got)
+	}
+
+	if _, err := m.Redo(ctx); err != nil {
+		t.Fatalf("Redo returned error: %v", err)
+	}
+	if got := current(); got != 1 {
+		t.Errorf("Current() after Redo = %d, want 1", got)
+	}
+
+	if applied, err := m.Up(ctx); err != nil || len(applied) != 1 {
+		t.Fatalf("Up = %v, %v, want 002_feeds applied again", applied, err)
+	}
+	if stepRuns != 2 {
+		t.Errorf("step ran %d times, want 2", stepRuns)
+	}
+
+	// A newer row rolling a version back hides older rows applying it
+	_, err = db.ExecContext(ctx, `INSERT INTO `+versionTable+` (version_id, is_applied) VALUES (2, FALSE)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := current(); got != 1 {
+		t.Errorf("Current() after goose rolled back version 2 = %d, want 1", got)
+	}
+}
@@ -0,0 +1,111 @@
+package rssfeeds
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html"
+	"strings"
+)
+
+// atomFeed is an Atom <feed> document (RFC 4287)
+type atomFeed struct {
+	Title     atomText    `xml:"title"`
+	Subtitle  atomText    `xml:"subtitle"`
+	Links     []RSSLink   `xml:"link"`
+	Language  string      `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	Generator string      `xml:"generator"`
+	Logo      string      `xml:"logo"`
+	Icon      string      `xml:"icon"`
+	Authors   []RSSAuthor `xml:"author"` // Authors of entries that name none
+	Entries   []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	Title      atomText      `xml:"title"`
+	Links      []RSSLink     `xml:"link"`
+	Summary    atomText      `xml:"summary"`
+	Content    atomText      `xml:"content"`
+	Published  string        `xml:"published"`
+	Updated    string        `xml:"updated"`
+	Categories []RSSCategory `xml:"category"`
+	Authors    []RSSAuthor   `xml:"author"`
+}
+
+// atomText is an Atom text construct. Plain text and escaped HTML are
+// character data, while type="xhtml" wraps markup in a <div>
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Text  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+func (t atomText) String() string {
+	if t.Type == "xhtml" {
+		return strings.TrimSpace(t.Inner)
+	}
+	return strings.TrimSpace(t.Text)
+}
+
+// plain returns the construct as plain text, as RSS titles are. Titles
+// of type="html" are unescaped, so "&amp;" reads as "&"
+func (t atomText) plain() string {
+	if t.Type == "html" {
+		return strings.TrimSpace(html.UnescapeString(t.Text))
+	}
+	return t.String()
+}
+
+// alternateLink returns the page a feed or entry links to: the
+// rel="alternate" link, which is also what a link without rel means
+func alternateLink(links []RSSLink) string {
+	for _, link := range links {
+		if (link.Rel == "" || link.Rel == "alternate") && link.Href != "" {
+			return link.Href
+		}
+	}
+	return ""
+}
+
+// parseAtom maps an Atom feed onto the RSS structure the rest of GoFlux
+// reads, keeping its links so hubs and self URLs are found as in RSS
+func parseAtom(bodyBytes []byte) (*RSSFeed, error) {
+	var atom atomFeed
+	if err := xml.Unmarshal(bodyBytes, &atom); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal Atom: %v", err)
+	}
+
+	var feed RSSFeed
+	feed.Channel.Title = atom.Title.plain()
+	feed.Channel.AtomLinks = atom.Links
+	feed.Channel.Link = alternateLink(atom.Links)
+	feed.Channel.Description = atom.Subtitle.String()
+	feed.Channel.Language = atom.Language
+	feed.Channel.Generator = strings.TrimSpace(atom.Generator)
+	feed.Channel.Image.URL = strings.TrimSpace(atom.Logo)
+	if feed.Channel.Image.URL == "" {
+		feed.Channel.Image.URL = strings.TrimSpace(atom.Icon)
+	}
+
+	for _, entry := range atom.Entries {
+		item := RSSItem{
+			Title:       entry.Title.plain(),
+			Link:        alternateLink(entry.Links),
+			Description: entry.Summary.String(),
+			PubDate:     strings.TrimSpace(entry.Published),
+			Categories:  entry.Categories,
+			Authors:     entry.Authors,
+		}
+		if len(item.Authors) == 0 {
+			item.Authors = atom.Authors
+		}
+		if item.Description == "" {
+			item.Description = entry.Content.String()
+		}
+		if item.PubDate == "" {
+			item.PubDate = strings.TrimSpace(entry.Updated)
+		}
+		feed.Channel.Item = append(feed.Channel.Item, item)
+	}
+
+	return &feed, nil
+}
@@ -0,0 +1,148 @@
+package rssfeeds
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// maxPageBytes bounds how much of an article is read looking for its
+// canonical link, which belongs in the <head>
+const maxPageBytes = 512 << 10
+
+var (
+	linkTag   = regexp.MustCompile(`(?is)<link\b[^>]*>`)
+	relAttr   = regexp.MustCompile(`(?is)\brel\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+	hrefAttr  = regexp.MustCompile(`(?is)\bhref\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+	headClose = regexp.MustCompile(`(?i)</head>`)
+)
+
+// maxCanonicalRedirects is how many redirects are followed to an
+// article, as many as net/http follows by default
+const maxCanonicalRedirects = 10
+
+// ResolveCanonical returns the URL an article is best known by: the
+// page's rel=canonical link if it declares one, otherwise where its
+// redirects end. The request is made with opts, like the feed's own.
+// When opts carry credentials, redirects may only change the scheme or
+// query: a page that moves to another host or path is likely a login
+// wall and is left unresolved. Pages disallowed by robots.txt are not
+// fetched
+func (f *Fetcher) ResolveCanonical(ctx context.Context, pageURL string, opts FetchOptions) (string, error) {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid url: %v", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return pageURL, nil
+	}
+
+	host := f.host(u.Host)
+	if !f.cfg.IgnoreRobots && !f.robotsFor(ctx, host, u, opts).allowed(u.EscapedPath()) {
+		return pageURL, nil
+	}
+
+	release, err := f.acquire(ctx, host, u.Host)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	fetchCtx, cancel := f.withTimeout(ctx)
+	defer cancel()
+
+	req, err := opts.newRequest(fetchCtx, pageURL)
+	if err != nil {
+		return "", err
+	}
+
+	client, err := opts.client()
+	if err != nil {
+		return "", err
+	}
+	client.CheckRedirect = func(next *http.Request, via []*http.Request) error {
+		if len(via) >= maxCanonicalRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxCanonicalRedirects)
+		}
+		if opts.credentials() && !sameResource(u, next.URL) {
+			return http.ErrUseLastResponse
+		}
+		return nil
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("cannot get response: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if isRedirect(resp.StatusCode) {
+		slog.Debug("article redirects elsewhere, keeping its url", "url", u.Redacted(), "location", resp.Header.Get("Location"))
+		return pageURL, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", &StatusError{StatusCode: resp.StatusCode}
+	}
+
+	final := resp.Request.URL
+	slog.Debug("resolved article url", "url", u.Redacted(), "final_url", final.Redacted())
+	if !strings.Contains(resp.Header.Get("Content-Type"), "html") {
+		return final.String(), nil
+	}
+
+	page, err := io.ReadAll(io.LimitReader(resp.Body, maxPageBytes))
+	if err != nil {
+		return "", fmt.Errorf("cannot read body: %w", err)
+	}
+	if href := canonicalLink(page); href != "" {
+		canonical, err := final.Parse(href)
+		if err == nil && (canonical.Scheme == "http" || canonical.Scheme == "https") {
+			return canonical.String(), nil
+		}
+	}
+	return final.String(), nil
+}
+
+// sameResource reports whether a redirect from a to b keeps the host
+// and path, ignoring a trailing slash
+func sameResource(a, b *url.URL) bool {
+	return strings.EqualFold(a.Hostname(), b.Hostname()) &&
+		strings.TrimRight(a.Path, "/") == strings.TrimRight(b.Path, "/")
+}
+
+func isRedirect(status int) bool {
+	return status >= 300 && status < 400
+}
+
+// canonicalLink finds the href of <link rel="canonical"> in a page's head
+func canonicalLink(page []byte) string {
+	if loc := headClose.FindIndex(page); loc != nil {
+		page = page[:loc[0]]
+	}
+	for _, tag := range linkTag.FindAll(page, -1) {
+		rel := attrValue(relAttr, tag)
+		if !strings.Contains(" "+strings.ToLower(rel)+" ", " canonical ") {
+			continue
+		}
+		return strings.TrimSpace(html.UnescapeString(attrValue(hrefAttr, tag)))
+	}
+	return ""
+}
+
+func attrValue(attr *regexp.Regexp, tag []byte) string {
+	match := attr.FindSubmatch(tag)
+	if match == nil {
+		return ""
+	}
+	for _, group := range match[1:] {
+		if len(group) > 0 {
+			return string(group)
+		}
+	}
+	return ""
+}
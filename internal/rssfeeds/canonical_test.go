@@ -0,0 +1,96 @@
+package rssfeeds
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestResolveCanonical(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/canonical", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><link rel="canonical" href="/original"></head><body></body></html>`))
+	})
+	mux.HandleFunc("/plain", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head></head><body></body></html>`))
+	})
+	mux.HandleFunc("/same", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/same/?page=1", http.StatusFound)
+	})
+	mux.HandleFunc("/same/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/articles/moved", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/articles/moved", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+	})
+	mux.HandleFunc("/members", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/login", http.StatusFound)
+	})
+	mux.HandleFunc("/private", func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "reader" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<link rel="canonical" href="/original">`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	// Redirects from a tracking host to the publisher's. Both run on
+	// 127.0.0.1, so the article is addressed as localhost
+	article := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<link rel="canonical" href="/2026/story">`))
+	}))
+	defer article.Close()
+	articleURL := strings.Replace(article.URL, "127.0.0.1", "localhost", 1)
+	mux.HandleFunc("/click", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, articleURL+"/story", http.StatusFound)
+	})
+
+	fetcher := NewFetcher(PolitenessConfig{IgnoreRobots: true})
+	credentials := FetchOptions{Username: "reader", Password: "secret"}
+	tests := []struct {
+		name    string
+		path    string
+		opts    FetchOptions
+		want    string
+		wantErr bool
+	}{
+		{name: "canonical link", path: "/canonical", want: server.URL + "/original"},
+		{name: "no canonical link", path: "/plain", want: server.URL + "/plain"},
+		{name: "redirect keeping the path", path: "/same", want: server.URL + "/same/?page=1"},
+		{name: "redirect to another path", path: "/moved", want: server.URL + "/articles/moved"},
+		{name: "redirect to another host", path: "/click", want: articleURL + "/2026/story"},
+		{name: "redirect to another path with credentials", path: "/members", opts: credentials, want: server.URL + "/members"},
+		{name: "redirect to another host with credentials", path: "/click", opts: credentials, want: server.URL + "/click"},
+		{name: "feed credentials", path: "/private", opts: credentials, want: server.URL + "/original"},
+		{name: "without credentials", path: "/private", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fetcher.ResolveCanonical(context.Background(), server.URL+tt.path, tt.opts)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ResolveCanonical(%s) = %q, want an error", tt.path, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveCanonical(%s) returned error: %v", tt.path, err)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveCanonical(%s) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,63 +1,319 @@
 package rssfeeds
 
 import (
+	"bytes"
 	"context"
 	"encoding/xml"
 	"fmt"
 	"html"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	atomNamespace = "http://www.w3.org/2005/Atom"
+	rdfNamespace  = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
 )
 
 type RSSFeed struct {
 	Channel struct {
-		Title       string    `xml:"title"`
+		Title string `xml:"title"`
+		// AtomLinks must precede Link, otherwise <atom:link> is matched by the plain "link" tag
+		AtomLinks   []RSSLink `xml:"http://www.w3.org/2005/Atom link"`
 		Link        string    `xml:"link"`
 		Description string    `xml:"description"`
+		Language    string    `xml:"language"`
+		Generator   string    `xml:"generator"`
+		Image       RSSImage  `xml:"image"`
 		Item        []RSSItem `xml:"item"`
 	} `xml:"channel"`
 }
 
+// RSSImage is the channel's <image>. Podcast feeds give an
+// <itunes:image href="..."> instead, which lands in Href
+type RSSImage struct {
+	URL  string `xml:"url"`
+	Href string `xml:"href,attr"`
+}
+
+// RSSLink is an <atom:link> element, used by feeds to advertise
+// their canonical URL (rel="self") and WebSub hubs (rel="hub")
+type RSSLink struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+}
+
 type RSSItem struct {
-	Title       string `xml:"title"`
-	Link        string `xml:"link"`
-	Description string `xml:"description"`
-	PubDate     string `xml:"pubDate"`
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	Description string        `xml:"description"`
+	PubDate     string        `xml:"pubDate"`
+	Categories  []RSSCategory `xml:"category"`
+	Subjects    []string      `xml:"http://purl.org/dc/elements/1.1/ subject"`
+	Authors     []RSSAuthor   `xml:"author"`
+	Creators    []string      `xml:"http://purl.org/dc/elements/1.1/ creator"`
 }
 
-func FetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
+// RSSAuthor is an RSS <author>, usually "email (Name)", or an Atom
+// <author> with a <name> child
+type RSSAuthor struct {
+	Text string `xml:",chardata"`
+	Name string `xml:"name"`
+}
+
+// name returns the author's display name
+func (a RSSAuthor) name() string {
+	if a.Name != "" {
+		return a.Name
+	}
+	// "jane@example.com (Jane Doe)" names Jane Doe
+	text := strings.TrimSpace(a.Text)
+	if open := strings.Index(text, "("); open > 0 && strings.HasSuffix(text, ")") {
+		return text[open+1 : len(text)-1]
+	}
+	return text
+}
+
+// Byline joins the item's authors and dc:creator values, or returns
+// an empty string when it names none
+func (item RSSItem) Byline() string {
+	var names []string
+	for _, author := range item.Authors {
+		names = append(names, author.name())
+	}
+	names = append(names, item.Creators...)
+	return strings.Join(uniqueNames(names), ", ")
+}
+
+// RSSCategory is an RSS <category>name</category> or an Atom
+// <category term="name"/>
+type RSSCategory struct {
+	Name string `xml:",chardata"`
+	Term string `xml:"term,attr"`
+}
+
+// CategoryNames returns the item's categories and dc:subject values,
+// trimmed and without duplicates that differ only in case
+func (item RSSItem) CategoryNames() []string {
+	var names []string
+	for _, category := range item.Categories {
+		if category.Term != "" {
+			names = append(names, category.Term)
+		} else {
+			names = append(names, category.Name)
+		}
+	}
+	names = append(names, item.Subjects...)
+	return uniqueNames(names)
+}
+
+// uniqueNames trims names and drops empty ones and duplicates that
+// differ only in case
+func uniqueNames(names []string) []string {
+	var unique []string
+	seen := make(map[string]bool)
+	for _, name := range names {
+		name = strings.TrimSpace(html.UnescapeString(name))
+		key := strings.ToLower(name)
+		if name == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, name)
+	}
+	return unique
+}
+
+// defaultUserAgent is sent when a feed has no custom User-Agent
+const defaultUserAgent = "GoFlux"
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+// FetchOptions holds per-feed HTTP settings such as credentials,
+// extra headers and proxying
+type FetchOptions struct {
+	UserAgent   string            `json:"user_agent,omitempty"`
+	Username    string            `json:"username,omitempty"`     // Basic auth user
+	Password    string            `json:"password,omitempty"`     // Basic auth password
+	BearerToken string            `json:"bearer_token,omitempty"` // Sent as "Authorization: Bearer <token>"
+	Headers     map[string]string `json:"headers,omitempty"`
+	Cookies     map[string]string `json:"cookies,omitempty"`
+	ProxyURL    string            `json:"proxy_url,omitempty"` // http://, https:// or socks5:// proxy
+}
+
+// StatusError is returned when a feed responds with a non-200 status
+type StatusError struct {
+	StatusCode int
+	RetryAfter time.Duration // Zero when the server sent no Retry-After
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+}
+
+// userAgent returns the User-Agent to send for these options
+func (opts FetchOptions) userAgent() string {
+	if opts.UserAgent != "" {
+		return opts.UserAgent
+	}
+	return defaultUserAgent
+}
+
+// newRequest builds a GET request carrying the User-Agent, headers,
+// cookies and credentials of these options
+func (opts FetchOptions) newRequest(ctx context.Context, rawURL string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("request error :%v", err)
+		return nil, fmt.Errorf("request error: %w", err)
+	}
+
+	req.Header.Set("User-Agent", opts.userAgent())
+
+	for name, value := range opts.Headers {
+		req.Header.Set(name, value)
 	}
+	for name, value := range opts.Cookies {
+		req.AddCookie(&http.Cookie{Name: name, Value: value})
+	}
+
+	if opts.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+opts.BearerToken)
+	} else if opts.Username != "" {
+		req.SetBasicAuth(opts.Username, opts.Password)
+	}
+	return req, nil
+}
+
+// credentials reports whether requests made with these options identify
+// the reader, through auth, cookies or custom headers
+func (opts FetchOptions) credentials() bool {
+	return opts.Username != "" || opts.BearerToken != "" || len(opts.Headers) > 0 || len(opts.Cookies) > 0
+}
 
-	req.Header.Set("User-Agent", "GoFlux")
+// client builds an HTTP client that routes through the configured proxy
+func (opts FetchOptions) client() (*http.Client, error) {
+	proxy := http.ProxyFromEnvironment
+	if opts.ProxyURL != "" {
+		proxyURL, err := url.Parse(opts.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy url: %v", err)
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = proxy
 
-	client := &http.Client{}
+	return &http.Client{Transport: transport}, nil
+}
+
+// parseRetryAfter reads a Retry-After header given either in seconds
+// or as an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(t); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
+
+func FetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
+	return FetchFeedWithOptions(ctx, feedURL, FetchOptions{})
+}
+
+// FetchFeedWithOptions fetches a feed applying its HTTP settings.
+// Without an explicit proxy the standard HTTP_PROXY/HTTPS_PROXY/NO_PROXY
+// environment variables are honored
+func FetchFeedWithOptions(ctx context.Context, feedURL string, opts FetchOptions) (*RSSFeed, error) {
+
+	req, err := opts.newRequest(ctx, feedURL)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := opts.client()
+	if err != nil {
+		return nil, err
+	}
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("cannot get response :%v", err)
+		return nil, fmt.Errorf("cannot get response: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, &StatusError{
+			StatusCode: resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
 	}
 
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("cannot read body: %v", err)
+		return nil, fmt.Errorf("cannot read body: %w", err)
 	}
 
+	return ParseFeed(bodyBytes)
+
+}
+
+// ParseFeed decodes an RSS, Atom or JSON Feed document, for fetched
+// feeds as well as content pushed by a WebSub hub
+func ParseFeed(bodyBytes []byte) (*RSSFeed, error) {
+	if trimmed := bytes.TrimLeft(bodyBytes, "\ufeff \t\r\n"); len(trimmed) > 0 && trimmed[0] == '{' {
+		return parseJSONFeed(trimmed)
+	}
+
+	root, err := rootElement(bodyBytes)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case root.Local == "rss":
+		return parseRSS(bodyBytes)
+	case root.Local == "RDF" && root.Space == rdfNamespace:
+		return parseRDF(bodyBytes)
+	case root.Local == "feed" && root.Space == atomNamespace:
+		return parseAtom(bodyBytes)
+	default:
+		return nil, fmt.Errorf("unsupported feed format: root element <%s>", root.Local)
+	}
+}
+
+// rootElement returns the name of the document's first element
+func rootElement(bodyBytes []byte) (xml.Name, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(bodyBytes))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return xml.Name{}, fmt.Errorf("cannot unmarshal XML: %v", err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name, nil
+		}
+	}
+}
+
+// parseRSS decodes an RSS 2.0 document
+func parseRSS(bodyBytes []byte) (*RSSFeed, error) {
 	var feed RSSFeed
-	if err = xml.Unmarshal(bodyBytes, &feed); err != nil {
+	if err := xml.Unmarshal(bodyBytes, &feed); err != nil {
 		return nil, fmt.Errorf("cannot unmarshal XML: %v", err)
 	}
 
 	feed.Channel.Title = html.UnescapeString(feed.Channel.Title)
 	feed.Channel.Description = html.UnescapeString(feed.Channel.Description)
+	feed.Channel.Image.URL = strings.TrimSpace(feed.Channel.Image.URL)
 
 	for _, item := range feed.Channel.Item {
 		item.Title = html.EscapeString(item.Title)
@@ -65,5 +321,65 @@ func FetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
 	}
 
 	return &feed, nil
+}
+
+// rdfFeed is an RSS 1.0 document, whose items follow the channel
+// instead of being inside it
+type rdfFeed struct {
+	Channel struct {
+		Title       string `xml:"title"`
+		Link        string `xml:"link"`
+		Description string `xml:"description"`
+	} `xml:"channel"`
+	Items []struct {
+		RSSItem
+		Date string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	} `xml:"item"`
+}
+
+// parseRDF decodes an RSS 1.0 document
+func parseRDF(bodyBytes []byte) (*RSSFeed, error) {
+	var rdf rdfFeed
+	if err := xml.Unmarshal(bodyBytes, &rdf); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal XML: %v", err)
+	}
+
+	var feed RSSFeed
+	feed.Channel.Title = html.UnescapeString(rdf.Channel.Title)
+	feed.Channel.Link = rdf.Channel.Link
+	feed.Channel.Description = html.UnescapeString(rdf.Channel.Description)
+	for _, entry := range rdf.Items {
+		item := entry.RSSItem
+		item.PubDate = strings.TrimSpace(entry.Date)
+		feed.Channel.Item = append(feed.Channel.Item, item)
+	}
 
+	return &feed, nil
+}
+
+// ImageURL returns the channel's image or icon, if any
+func (f *RSSFeed) ImageURL() string {
+	if f.Channel.Image.URL != "" {
+		return f.Channel.Image.URL
+	}
+	return f.Channel.Image.Href
+}
+
+// HubURL returns the first WebSub hub advertised by the feed, if any
+func (f *RSSFeed) HubURL() string {
+	return f.linkHref("hub")
+}
+
+// SelfURL returns the feed's self-declared canonical URL, if any
+func (f *RSSFeed) SelfURL() string {
+	return f.linkHref("self")
+}
+
+func (f *RSSFeed) linkHref(rel string) string {
+	for _, link := range f.Channel.AtomLinks {
+		if link.Rel == rel && link.Href != "" {
+			return link.Href
+		}
+	}
+	return ""
 }
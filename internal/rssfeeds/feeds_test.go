@@ -0,0 +1,209 @@
+package rssfeeds
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseFeed(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		title      string
+		link       string
+		hub        string
+		self       string
+		itemTitle  string
+		itemLink   string
+		itemDesc   string
+		pubDate    string
+		categories []string
+		byline     string
+	}{
+		{
+			name: "RSS",
+			body: `<?xml version="1.0"?>
+<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom" xmlns:dc="http://purl.org/dc/elements/1.1/">
+<channel>
+  <title>Example</title>
+  <link>https://example.com/</link>
+  <atom:link rel="hub" href="https://hub.example.com/"/>
+  <atom:link rel="self" href="https://example.com/rss"/>
+  <item>
+    <title>First</title>
+    <link>https://example.com/1</link>
+    <description>Hello</description>
+    <pubDate>Mon, 02 Jan 2006 15:04:05 +0000</pubDate>
+    <category>Go</category>
+    <dc:creator>Jane Doe</dc:creator>
+  </item>
+</channel>
+</rss>`,
+			title: "Example", link: "https://example.com/",
+			hub: "https://hub.example.com/", self: "https://example.com/rss",
+			itemTitle: "First", itemLink: "https://example.com/1", itemDesc: "Hello",
+			pubDate: "Mon, 02 Jan 2006 15:04:05 +0000", categories: []string{"Go"}, byline: "Jane Doe",
+		},
+		{
+			name: "RSS 1.0",
+			body: `<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <channel><title>Example</title><link>https://example.com/</link></channel>
+  <item>
+    <title>First</title>
+    <link>https://example.com/1</link>
+    <dc:date>2006-01-02T15:04:05Z</dc:date>
+  </item>
+</rdf:RDF>`,
+			title: "Example", link: "https://example.com/",
+			itemTitle: "First", itemLink: "https://example.com/1", pubDate: "2006-01-02T15:04:05Z",
+		},
+		{
+			name: "Atom",
+			body: `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xml:lang="en">
+  <title>Example</title>
+  <link href="https://example.com/"/>
+  <link rel="self" href="https://example.com/atom"/>
+  <link rel="hub" href="https://hub.example.com/"/>
+  <author><name>Jane Doe</name></author>
+  <entry>
+    <title type="html">First &amp;amp; best</title>
+    <link rel="alternate" href="https://example.com/1"/>
+    <link rel="edit" href="https://example.com/edit/1"/>
+    <updated>2006-01-03T15:04:05Z</updated>
+    <published>2006-01-02T15:04:05Z</published>
+    <category term="Go"/>
+    <category term="go"/>
+    <content type="html">&lt;p&gt;Hello&lt;/p&gt;</content>
+  </entry>
+</feed>`,
+			title: "Example", link: "https://example.com/",
+			hub: "https://hub.example.com/", self: "https://example.com/atom",
+			itemTitle: "First & best", itemLink: "https://example.com/1", itemDesc: "<p>Hello</p>",
+			pubDate: "2006-01-02T15:04:05Z", categories: []string{"Go"}, byline: "Jane Doe",
+		},
+		{
+			name: "Atom entry authors and summary",
+			body: `<feed xmlns="http://www.w3.org/2005/Atom">
+  <title type="html">News &amp;amp; &lt;Notes&gt;</title>
+  <author><name>Feed Author</name></author>
+  <entry>
+    <title>First</title>
+    <link href="https://example.com/1"/>
+    <updated>2006-01-03T15:04:05Z</updated>
+    <author><name>Jane Doe</name></author>
+    <author><name>John Roe</name></author>
+    <summary>Short</summary>
+    <content type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml">Long</div></content>
+  </entry>
+</feed>`,
+			title:     "News & <Notes>",
+			itemTitle: "First", itemLink: "https://example.com/1", itemDesc: "Short",
+			pubDate: "2006-01-03T15:04:05Z", byline: "Jane Doe, John Roe",
+		},
+		{
+			name: "JSON Feed 1.1",
+			body: `{
+  "version": "https://jsonfeed.org/version/1.1",
+  "title": "Example",
+  "home_page_url": "https://example.com/",
+  "feed_url": "https://example.com/feed.json",
+  "hubs": [{"type": "WebSub", "url": "https://hub.example.com/"}],
+  "authors": [{"name": "Feed Author"}],
+  "items": [{
+    "id": "1",
+    "url": "https://example.com/1",
+    "title": "First",
+    "content_html": "<p>Hello</p>",
+    "date_published": "2006-01-02T15:04:05Z",
+    "tags": ["Go", "go", "Web"],
+    "authors": [{"name": "Jane Doe"}, {"name": "John Roe"}]
+  }]
+}`,
+			title: "Example", link: "https://example.com/",
+			hub: "https://hub.example.com/", self: "https://example.com/feed.json",
+			itemTitle: "First", itemLink: "https://example.com/1", itemDesc: "<p>Hello</p>",
+			pubDate: "2006-01-02T15:04:05Z", categories: []string{"Go", "Web"}, byline: "Jane Doe, John Roe",
+		},
+		{
+			name: "JSON Feed 1.0 with feed author",
+			body: `{
+  "version": "https://jsonfeed.org/version/1",
+  "title": "Example",
+  "author": {"name": "Jane Doe"},
+  "items": [{"id": "1", "url": "https://example.com/1", "content_text": "Hello", "date_modified": "2006-01-03T15:04:05Z"}]
+}`,
+			title:    "Example",
+			itemLink: "https://example.com/1", itemDesc: "Hello",
+			pubDate: "2006-01-03T15:04:05Z", byline: "Jane Doe",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feed, err := ParseFeed([]byte(tt.body))
+			if err != nil {
+				t.Fatalf("ParseFeed returned error: %v", err)
+			}
+			if feed.Channel.Title != tt.title {
+				t.Errorf("title = %q, want %q", feed.Channel.Title, tt.title)
+			}
+			if feed.Channel.Link != tt.link {
+				t.Errorf("link = %q, want %q", feed.Channel.Link, tt.link)
+			}
+			if got := feed.HubURL(); got != tt.hub {
+				t.Errorf("HubURL() = %q, want %q", got, tt.hub)
+			}
+			if got := feed.SelfURL(); got != tt.self {
+				t.Errorf("SelfURL() = %q, want %q", got, tt.self)
+			}
+			if len(feed.Channel.Item) != 1 {
+				t.Fatalf("got %d items, want 1", len(feed.Channel.Item))
+			}
+
+			item := feed.Channel.Item[0]
+			if item.Title != tt.itemTitle {
+				t.Errorf("item title = %q, want %q", item.Title, tt.itemTitle)
+			}
+			if item.Link != tt.itemLink {
+				t.Errorf("item link = %q, want %q", item.Link, tt.itemLink)
+			}
+			if item.Description != tt.itemDesc {
+				t.Errorf("item description = %q, want %q", item.Description, tt.itemDesc)
+			}
+			if item.PubDate != tt.pubDate {
+				t.Errorf("item date = %q, want %q", item.PubDate, tt.pubDate)
+			}
+			if got := item.CategoryNames(); !reflect.DeepEqual(got, tt.categories) {
+				t.Errorf("categories = %q, want %q", got, tt.categories)
+			}
+			if got := item.Byline(); got != tt.byline {
+				t.Errorf("byline = %q, want %q", got, tt.byline)
+			}
+		})
+	}
+}
+
+func TestParseFeedErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{name: "unknown root", body: `<html><body>Not a feed</body></html>`, want: "unsupported feed format: root element <html>"},
+		{name: "feed outside the Atom namespace", body: `<feed><entry/></feed>`, want: "unsupported feed format"},
+		{name: "empty", body: ``, want: "cannot unmarshal XML"},
+		{name: "invalid JSON", body: `{"version": `, want: "cannot unmarshal JSON Feed"},
+		{name: "JSON without feed version", body: `{"title": "Example"}`, want: "not a JSON Feed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFeed([]byte(tt.body))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseFeed error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
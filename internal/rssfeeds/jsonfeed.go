@@ -0,0 +1,126 @@
+package rssfeeds
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// jsonFeed is a JSON Feed document, version 1.0 or 1.1
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Description string         `json:"description"`
+	Language    string         `json:"language"`
+	Icon        string         `json:"icon"`
+	Favicon     string         `json:"favicon"`
+	Hubs        []jsonFeedHub  `json:"hubs"`
+	Items       []jsonFeedItem `json:"items"`
+	// Authors of items that name none
+	Authors []jsonFeedAuthor `json:"authors"`
+	Author  *jsonFeedAuthor  `json:"author"`
+}
+
+type jsonFeedHub struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+type jsonFeedItem struct {
+	ID            string   `json:"id"`
+	URL           string   `json:"url"`
+	Title         string   `json:"title"`
+	ContentHTML   string   `json:"content_html"`
+	ContentText   string   `json:"content_text"`
+	Summary       string   `json:"summary"`
+	DatePublished string   `json:"date_published"`
+	DateModified  string   `json:"date_modified"`
+	Tags          []string `json:"tags"`
+	// Version 1.1 lists authors, 1.0 had a single author
+	Authors []jsonFeedAuthor `json:"authors"`
+	Author  *jsonFeedAuthor  `json:"author"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+func (doc jsonFeed) authors() []jsonFeedAuthor {
+	return mergeJSONAuthors(doc.Authors, doc.Author)
+}
+
+func (entry jsonFeedItem) authors() []jsonFeedAuthor {
+	return mergeJSONAuthors(entry.Authors, entry.Author)
+}
+
+// mergeJSONAuthors joins the version 1.1 authors list with the version
+// 1.0 author
+func mergeJSONAuthors(authors []jsonFeedAuthor, author *jsonFeedAuthor) []jsonFeedAuthor {
+	if author != nil {
+		authors = append(authors, *author)
+	}
+	return authors
+}
+
+// parseJSONFeed maps a JSON Feed onto the RSS structure the rest of
+// GoFlux reads. Its feed URL and WebSub hubs become the matching links
+func parseJSONFeed(bodyBytes []byte) (*RSSFeed, error) {
+	var doc jsonFeed
+	if err := json.Unmarshal(bodyBytes, &doc); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal JSON Feed: %v", err)
+	}
+	if !strings.HasPrefix(doc.Version, "https://jsonfeed.org/version/") {
+		return nil, fmt.Errorf("not a JSON Feed: unknown version %q", doc.Version)
+	}
+
+	var feed RSSFeed
+	feed.Channel.Title = strings.TrimSpace(doc.Title)
+	feed.Channel.Link = doc.HomePageURL
+	feed.Channel.Description = strings.TrimSpace(doc.Description)
+	feed.Channel.Language = doc.Language
+	feed.Channel.Image.URL = doc.Icon
+	if feed.Channel.Image.URL == "" {
+		feed.Channel.Image.URL = doc.Favicon
+	}
+	if doc.FeedURL != "" {
+		feed.Channel.AtomLinks = append(feed.Channel.AtomLinks, RSSLink{Rel: "self", Href: doc.FeedURL})
+	}
+	for _, hub := range doc.Hubs {
+		if strings.EqualFold(hub.Type, "WebSub") && hub.URL != "" {
+			feed.Channel.AtomLinks = append(feed.Channel.AtomLinks, RSSLink{Rel: "hub", Href: hub.URL})
+		}
+	}
+
+	for _, entry := range doc.Items {
+		item := RSSItem{
+			Title:       strings.TrimSpace(entry.Title),
+			Link:        entry.URL,
+			Description: entry.Summary,
+			PubDate:     entry.DatePublished,
+		}
+		if item.Description == "" {
+			item.Description = entry.ContentHTML
+		}
+		if item.Description == "" {
+			item.Description = entry.ContentText
+		}
+		if item.PubDate == "" {
+			item.PubDate = entry.DateModified
+		}
+		for _, tag := range entry.Tags {
+			item.Categories = append(item.Categories, RSSCategory{Name: tag})
+		}
+		authors := entry.authors()
+		if len(authors) == 0 {
+			authors = doc.authors()
+		}
+		for _, author := range authors {
+			item.Authors = append(item.Authors, RSSAuthor{Name: author.Name})
+		}
+		feed.Channel.Item = append(feed.Channel.Item, item)
+	}
+
+	return &feed, nil
+}
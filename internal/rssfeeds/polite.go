@@ -0,0 +1,240 @@
+package rssfeeds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	// robotsCacheTTL is how long a host's robots.txt is reused
+	robotsCacheTTL = 24 * time.Hour
+	// robotsRetryInterval is how soon an unavailable robots.txt is tried again
+	robotsRetryInterval = 10 * time.Minute
+	// defaultRetryAfter backs off a host that sent 429 without Retry-After
+	defaultRetryAfter = time.Minute
+)
+
+// PolitenessConfig controls how hard the Fetcher may hit a single host
+type PolitenessConfig struct {
+	MinHostInterval    time.Duration // Minimum time between request starts to one host
+	MaxHostConcurrency int           // Maximum parallel requests to one host, at least 1
+	IgnoreRobots       bool          // Skip robots.txt checks
+	FetchTimeout       time.Duration // Limit on each request once it is sent, zero for none
+}
+
+// Fetcher fetches feeds while enforcing per-host rate limits,
+// robots.txt rules and server-requested back-off
+type Fetcher struct {
+	cfg   PolitenessConfig
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+// hostState tracks the politeness bookkeeping of one host
+type hostState struct {
+	slots         chan struct{}
+	nextRequest   time.Time
+	blockedUntil  time.Time
+	robots        *robotsRules
+	robotsExpires time.Time
+}
+
+// NewFetcher creates a Fetcher with the given politeness settings
+func NewFetcher(cfg PolitenessConfig) *Fetcher {
+	if cfg.MaxHostConcurrency < 1 {
+		cfg.MaxHostConcurrency = 1
+	}
+	return &Fetcher{
+		cfg:   cfg,
+		hosts: make(map[string]*hostState),
+	}
+}
+
+// Fetch fetches a feed like FetchFeedWithOptions, waiting for the host's
+// turn first. It fails fast while the host is backing off after a 429/503
+func (f *Fetcher) Fetch(ctx context.Context, feedURL string, opts FetchOptions) (*RSSFeed, error) {
+	u, err := url.Parse(feedURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid feed url: %v", err)
+	}
+
+	host := f.host(u.Host)
+
+	if !f.cfg.IgnoreRobots {
+		rules := f.robotsFor(ctx, host, u, opts)
+		if rules.unavailable {
+			return nil, fmt.Errorf("cannot fetch %s while robots.txt of %s is unavailable", feedURL, u.Host)
+		}
+		if !rules.allowed(u.EscapedPath()) {
+			return nil, fmt.Errorf("fetching %s is disallowed by robots.txt", feedURL)
+		}
+	}
+
+	release, err := f.acquire(ctx, host, u.Host)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// The timeout starts after waiting for the host, so queueing is not counted
+	fetchCtx, cancel := f.withTimeout(ctx)
+	defer cancel()
+
+	start := time.Now()
+	feed, err := FetchFeedWithOptions(fetchCtx, feedURL, opts)
+	slog.Debug("fetched feed over http", "url", u.Redacted(), "duration", time.Since(start), "error", err)
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && (statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode == http.StatusServiceUnavailable) {
+		wait := statusErr.RetryAfter
+		if wait == 0 {
+			wait = defaultRetryAfter
+		}
+		f.mu.Lock()
+		host.blockedUntil = time.Now().Add(wait)
+		f.mu.Unlock()
+		slog.Warn("host asked to back off", "host", u.Host, "status", statusErr.StatusCode, "retry_after", wait)
+	}
+
+	return feed, err
+}
+
+// withTimeout bounds a single request by the configured fetch timeout
+func (f *Fetcher) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if f.cfg.FetchTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, f.cfg.FetchTimeout)
+}
+
+func (f *Fetcher) host(name string) *hostState {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	host, ok := f.hosts[name]
+	if !ok {
+		host = &hostState{slots: make(chan struct{}, f.cfg.MaxHostConcurrency)}
+		f.hosts[name] = host
+	}
+	return host
+}
+
+// acquire takes a concurrency slot for the host and waits out the minimum
+// interval since the previous request. The returned func frees the slot
+func (f *Fetcher) acquire(ctx context.Context, host *hostState, name string) (func(), error) {
+	select {
+	case host.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	release := func() { <-host.slots }
+
+	f.mu.Lock()
+	if until := host.blockedUntil; time.Now().Before(until) {
+		f.mu.Unlock()
+		release()
+		return nil, fmt.Errorf("host %s asked to back off until %s", name, until.Format(time.RFC1123))
+	}
+
+	interval := f.cfg.MinHostInterval
+	if host.robots != nil && host.robots.crawlDelay > interval {
+		interval = host.robots.crawlDelay
+	}
+
+	// Reserve the next start time so concurrent callers queue up behind it
+	start := time.Now()
+	if host.nextRequest.After(start) {
+		start = host.nextRequest
+	}
+	host.nextRequest = start.Add(interval)
+	f.mu.Unlock()
+
+	if wait := time.Until(start); wait > 0 {
+		slog.Debug("waiting for host interval", "host", name, "wait", wait)
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+	}
+
+	return release, nil
+}
+
+// robotsFor returns the cached robots.txt rules of a host, refreshing them
+// when stale. Following RFC 9309, a missing robots.txt (4xx) allows
+// everything, while server or network errors disallow everything until
+// a later fetch succeeds. A previously fetched copy is kept meanwhile
+func (f *Fetcher) robotsFor(ctx context.Context, host *hostState, u *url.URL, opts FetchOptions) *robotsRules {
+	f.mu.Lock()
+	if host.robots != nil && time.Now().Before(host.robotsExpires) {
+		rules := host.robots
+		f.mu.Unlock()
+		return rules
+	}
+	f.mu.Unlock()
+
+	robotsCtx, cancel := f.withTimeout(ctx)
+	defer cancel()
+
+	rules := fetchRobots(robotsCtx, u, opts)
+	slog.Debug("refreshed robots.txt", "host", u.Host, "rules", len(rules.rules), "crawl_delay", rules.crawlDelay, "unavailable", rules.unavailable)
+
+	// A fetch cut short by shutdown says nothing about the host
+	if ctx.Err() != nil {
+		return rules
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !rules.unavailable {
+		host.robots = rules
+		host.robotsExpires = time.Now().Add(robotsCacheTTL)
+		return rules
+	}
+	if host.robots == nil || host.robots.unavailable {
+		host.robots = rules
+	}
+	host.robotsExpires = time.Now().Add(robotsRetryInterval)
+	return host.robots
+}
+
+func fetchRobots(ctx context.Context, u *url.URL, opts FetchOptions) *robotsRules {
+	robotsURL := url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/robots.txt"}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsURL.String(), nil)
+	if err != nil {
+		return &robotsRules{}
+	}
+	req.Header.Set("User-Agent", opts.userAgent())
+
+	client, err := opts.client()
+	if err != nil {
+		return &robotsRules{}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return &robotsRules{unavailable: true}
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return parseRobots(resp.Body, defaultUserAgent)
+	case resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests:
+		// 429 is a server asking to slow down, not a missing file
+		return &robotsRules{unavailable: true}
+	default:
+		return &robotsRules{}
+	}
+}
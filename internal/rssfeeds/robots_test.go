@@ -0,0 +1,113 @@
+package rssfeeds
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRobotsMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{pattern: "/", path: "/feed.xml", want: true},
+		{pattern: "/private", path: "/private/feed.xml", want: true},
+		{pattern: "/private", path: "/public/feed.xml", want: false},
+		{pattern: "/*.xml", path: "/blog/feed.xml", want: true},
+		{pattern: "/*.xml", path: "/blog/feed.json", want: false},
+		{pattern: "/*.xml$", path: "/feed.xml", want: true},
+		{pattern: "/*.xml$", path: "/feed.xml?page=2", want: false},
+		{pattern: "/feed$", path: "/feed", want: true},
+		{pattern: "/feed$", path: "/feeds", want: false},
+		{pattern: "/a*b*c", path: "/a-b-c-d", want: true},
+		{pattern: "/a*c*b", path: "/a-b-c", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := robotsMatch(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("robotsMatch(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestParseRobots(t *testing.T) {
+	const body = `# Example robots.txt
+User-agent: *
+Disallow: /private
+Crawl-delay: 2
+
+User-agent: GoFlux
+User-agent: OtherBot
+Disallow: /
+Allow: /feeds/
+Allow: /private$ # not a prefix
+Crawl-delay: 0.5
+
+User-agent: EmptyBot
+Disallow:
+`
+
+	tests := []struct {
+		name       string
+		userAgent  string
+		crawlDelay time.Duration
+		allowed    map[string]bool
+	}{
+		{
+			name:       "named group",
+			userAgent:  "GoFlux/1.0 (+https://example.com)",
+			crawlDelay: 500 * time.Millisecond,
+			allowed: map[string]bool{
+				"/feeds/rss.xml":  true,
+				"/about":          false,
+				"/private":        true,
+				"/private/secret": false,
+				"":                false,
+			},
+		},
+		{
+			name:       "second agent of a group",
+			userAgent:  "otherbot",
+			crawlDelay: 500 * time.Millisecond,
+			allowed:    map[string]bool{"/feeds/rss.xml": true, "/about": false},
+		},
+		{
+			name:       "wildcard group",
+			userAgent:  "SomeBot/2.0",
+			crawlDelay: 2 * time.Second,
+			allowed:    map[string]bool{"/feeds/rss.xml": true, "/private/feed": false},
+		},
+		{
+			name:      "empty disallow",
+			userAgent: "EmptyBot",
+			allowed:   map[string]bool{"/private/feed": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := parseRobots(strings.NewReader(body), tt.userAgent)
+			if rules.crawlDelay != tt.crawlDelay {
+				t.Errorf("crawl delay = %v, want %v", rules.crawlDelay, tt.crawlDelay)
+			}
+			for path, want := range tt.allowed {
+				if got := rules.allowed(path); got != want {
+					t.Errorf("allowed(%q) = %v, want %v", path, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRobotsUnavailable(t *testing.T) {
+	rules := &robotsRules{unavailable: true}
+	if rules.allowed("/feed.xml") {
+		t.Error("allowed = true for an unavailable robots.txt, want false")
+	}
+
+	if rules := parseRobots(strings.NewReader(""), "GoFlux"); !rules.allowed("/feed.xml") {
+		t.Error("allowed = false for an empty robots.txt, want true")
+	}
+}
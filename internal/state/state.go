@@ -3,18 +3,24 @@ package state
 import (
 	"github.com/twomotive/GoFlux/internal/config"
 	"github.com/twomotive/GoFlux/internal/database"
+	"github.com/twomotive/GoFlux/internal/migrate"
+	"github.com/twomotive/GoFlux/internal/rssfeeds"
 )
 
 // AppState holds the global application state
 type State struct {
-	DB  *database.Queries
-	Cfg *config.Config
+	DB       database.Querier
+	Cfg      *config.Config
+	Fetcher  *rssfeeds.Fetcher
+	Migrator *migrate.Migrator
 }
 
 // New creates a new AppState instance
-func New(db *database.Queries, cfg *config.Config) *State {
+func New(db database.Querier, cfg *config.Config, fetcher *rssfeeds.Fetcher, migrator *migrate.Migrator) *State {
 	return &State{
-		DB:  db,
-		Cfg: cfg,
+		DB:       db,
+		Cfg:      cfg,
+		Fetcher:  fetcher,
+		Migrator: migrator,
 	}
 }
@@ -0,0 +1,135 @@
+package textdiff
+
+import (
+	"strings"
+	"unicode"
+)
+
+// maxCells bounds the comparison table. Texts too long to compare word
+// by word are shown as entirely replaced
+const maxCells = 4_000_000
+
+// Op says whether a chunk of text was kept, removed or added
+type Op int
+
+const (
+	Equal Op = iota
+	Delete
+	Insert
+)
+
+// Chunk is a run of text with the same Op
+type Chunk struct {
+	Op   Op
+	Text string
+}
+
+// Words compares two texts word by word. Whitespace is kept, so joining
+// the Equal and Delete chunks gives a and the Equal and Insert chunks gives b
+func Words(a, b string) []Chunk {
+	x, y := tokenize(a), tokenize(b)
+	if len(x)*len(y) > maxCells {
+		return merge([]Chunk{{Delete, a}, {Insert, b}})
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of x[i:] and y[j:]
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var chunks []Chunk
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] == y[j]:
+			chunks = append(chunks, Chunk{Equal, x[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			chunks = append(chunks, Chunk{Delete, x[i]})
+			i++
+		default:
+			chunks = append(chunks, Chunk{Insert, y[j]})
+			j++
+		}
+	}
+	for ; i < len(x); i++ {
+		chunks = append(chunks, Chunk{Delete, x[i]})
+	}
+	for ; j < len(y); j++ {
+		chunks = append(chunks, Chunk{Insert, y[j]})
+	}
+	return merge(chunks)
+}
+
+// Format renders chunks like git's plain word diff: removed text in
+// [-...-] and added text in {+...+}
+func Format(chunks []Chunk) string {
+	var b strings.Builder
+	for _, c := range chunks {
+		switch c.Op {
+		case Equal:
+			b.WriteString(c.Text)
+		case Delete:
+			b.WriteString("[-" + c.Text + "-]")
+		case Insert:
+			b.WriteString("{+" + c.Text + "+}")
+		}
+	}
+	return b.String()
+}
+
+// Changed reports whether any chunk was removed or added
+func Changed(chunks []Chunk) bool {
+	for _, c := range chunks {
+		if c.Op != Equal {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenize splits text into words and the whitespace between them
+func tokenize(text string) []string {
+	var tokens []string
+	start := 0
+	inSpace := false
+	for i, r := range text {
+		space := unicode.IsSpace(r)
+		if i > start && space != inSpace {
+			tokens = append(tokens, text[start:i])
+			start = i
+		}
+		inSpace = space
+	}
+	if start < len(text) {
+		tokens = append(tokens, text[start:])
+	}
+	return tokens
+}
+
+// merge joins neighbouring chunks with the same Op, dropping empty ones
+func merge(chunks []Chunk) []Chunk {
+	var merged []Chunk
+	for _, c := range chunks {
+		if c.Text == "" {
+			continue
+		}
+		if n := len(merged); n > 0 && merged[n-1].Op == c.Op {
+			merged[n-1].Text += c.Text
+			continue
+		}
+		merged = append(merged, c)
+	}
+	return merged
+}
@@ -0,0 +1,61 @@
+package textdiff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		want    string
+		changed bool
+	}{
+		{name: "equal", a: "the quick fox", b: "the quick fox", want: "the quick fox"},
+		{name: "both empty", a: "", b: "", want: ""},
+		{name: "replaced word", a: "the quick fox", b: "the slow fox", want: "the [-quick-]{+slow+} fox", changed: true},
+		{name: "added word", a: "the fox", b: "the brown fox", want: "the {+brown +}fox", changed: true},
+		{name: "removed word", a: "the brown fox", b: "the fox", want: "the [-brown -]fox", changed: true},
+		{name: "from empty", a: "", b: "new text", want: "{+new text+}", changed: true},
+		{name: "to empty", a: "old text", b: "", want: "[-old text-]", changed: true},
+		{name: "whitespace only", a: "a b", b: "a  b", want: "a[- -]{+  +}b", changed: true},
+		{name: "multibyte", a: "café au lait", b: "café noir", want: "café [-au lait-]{+noir+}", changed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := Words(tt.a, tt.b)
+			if got := Format(chunks); got != tt.want {
+				t.Errorf("Format(Words(%q, %q)) = %q, want %q", tt.a, tt.b, got, tt.want)
+			}
+			if got := Changed(chunks); got != tt.changed {
+				t.Errorf("Changed = %v, want %v", got, tt.changed)
+			}
+
+			// Equal and Delete chunks give a, Equal and Insert chunks give b
+			var a, b strings.Builder
+			for _, c := range chunks {
+				if c.Op != Insert {
+					a.WriteString(c.Text)
+				}
+				if c.Op != Delete {
+					b.WriteString(c.Text)
+				}
+			}
+			if a.String() != tt.a || b.String() != tt.b {
+				t.Errorf("chunks rebuild %q and %q, want %q and %q", a.String(), b.String(), tt.a, tt.b)
+			}
+		})
+	}
+}
+
+func TestWordsTooLong(t *testing.T) {
+	a := strings.Repeat("a ", 2001)
+	b := strings.Repeat("b ", 2001)
+	chunks := Words(a, b)
+	want := []Chunk{{Delete, a}, {Insert, b}}
+	if len(chunks) != len(want) || chunks[0] != want[0] || chunks[1] != want[1] {
+		t.Errorf("Words of long texts = %d chunks, want the whole of a deleted and b inserted", len(chunks))
+	}
+}
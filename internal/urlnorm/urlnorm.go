@@ -0,0 +1,113 @@
+package urlnorm
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// TrackingParams are query parameters stripped from every URL. A
+// trailing * matches any suffix
+var TrackingParams = []string{
+	"utm_*",
+	"fbclid",
+	"gclid",
+	"dclid",
+	"gbraid",
+	"wbraid",
+	"msclkid",
+	"yclid",
+	"mc_cid",
+	"mc_eid",
+	"igshid",
+	"_hsenc",
+	"_hsmi",
+}
+
+// Normalizer turns URLs that point at the same resource into one form
+type Normalizer struct {
+	exact    map[string]bool
+	prefixes []string
+}
+
+// New creates a Normalizer stripping TrackingParams and the extra
+// parameters given, which use the same * syntax
+func New(extra []string) *Normalizer {
+	n := &Normalizer{exact: make(map[string]bool)}
+	for _, param := range append(append([]string(nil), TrackingParams...), extra...) {
+		param = strings.ToLower(strings.TrimSpace(param))
+		switch {
+		case param == "":
+		case strings.HasSuffix(param, "*"):
+			n.prefixes = append(n.prefixes, strings.TrimSuffix(param, "*"))
+		default:
+			n.exact[param] = true
+		}
+	}
+	return n
+}
+
+// defaultPorts are the ports implied by each scheme
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// Normalize returns the form used to recognize the same URL: scheme and
+// host are lower-cased, the scheme's default port, trailing slashes and
+// tracking parameters are dropped and the remaining parameters are
+// sorted. The scheme and fragment are kept, since they may tell
+// different resources apart. It is meant for comparison, the result is
+// not guaranteed to load the same page
+func (n *Normalizer) Normalize(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", err
+	}
+	if !u.IsAbs() || u.Host == "" {
+		return "", fmt.Errorf("not an absolute URL: %s", raw)
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+
+	host, port := strings.TrimSuffix(strings.ToLower(u.Hostname()), "."), u.Port()
+	switch {
+	case port != "" && port != defaultPorts[u.Scheme]:
+		u.Host = net.JoinHostPort(host, port)
+	case strings.Contains(host, ":"):
+		u.Host = "[" + host + "]"
+	default:
+		u.Host = host
+	}
+
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = strings.TrimRight(u.RawPath, "/")
+	if u.Path == "" {
+		u.Path, u.RawPath = "/", ""
+	}
+
+	query := u.Query()
+	for key := range query {
+		if n.tracking(key) {
+			query.Del(key)
+		}
+	}
+	u.RawQuery = query.Encode()
+	u.ForceQuery = false
+	return u.String(), nil
+}
+
+// tracking reports whether a query parameter only tracks the visitor
+func (n *Normalizer) tracking(key string) bool {
+	key = strings.ToLower(key)
+	if n.exact[key] {
+		return true
+	}
+	for _, prefix := range n.prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
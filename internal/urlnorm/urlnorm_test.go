@@ -0,0 +1,63 @@
+package urlnorm
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name  string
+		extra []string
+		raw   string
+		want  string
+	}{
+		{name: "already normalized", raw: "https://example.com/feed", want: "https://example.com/feed"},
+		{name: "lower-cases scheme and host", raw: "HTTPS://Example.COM/Feed", want: "https://example.com/Feed"},
+		{name: "keeps http", raw: "http://example.com/feed", want: "http://example.com/feed"},
+		{name: "drops trailing slash", raw: "https://example.com/feed/", want: "https://example.com/feed"},
+		{name: "keeps root path", raw: "https://example.com", want: "https://example.com/"},
+		{name: "drops trailing dot of host", raw: "https://example.com./feed", want: "https://example.com/feed"},
+		{name: "drops http default port", raw: "http://example.com:80/feed", want: "http://example.com/feed"},
+		{name: "drops https default port", raw: "https://example.com:443/feed", want: "https://example.com/feed"},
+		{name: "keeps https port on http", raw: "http://example.com:443/feed", want: "http://example.com:443/feed"},
+		{name: "keeps http port on https", raw: "https://example.com:80/feed", want: "https://example.com:80/feed"},
+		{name: "keeps other ports", raw: "https://example.com:8443/feed", want: "https://example.com:8443/feed"},
+		{name: "keeps IPv6 host", raw: "http://[::1]:80/feed", want: "http://[::1]/feed"},
+		{name: "keeps fragment", raw: "https://example.com/changelog#v1.2", want: "https://example.com/changelog#v1.2"},
+		{name: "strips tracking parameters", raw: "https://example.com/a?utm_source=x&UTM_Medium=y&fbclid=z&id=1", want: "https://example.com/a?id=1"},
+		{name: "drops empty query", raw: "https://example.com/a?utm_source=x", want: "https://example.com/a"},
+		{name: "sorts parameters", raw: "https://example.com/a?b=2&a=1", want: "https://example.com/a?a=1&b=2"},
+		{name: "strips extra exact parameter", extra: []string{"ref"}, raw: "https://example.com/a?ref=home&id=1", want: "https://example.com/a?id=1"},
+		{name: "strips extra prefix parameter", extra: []string{" trk_* "}, raw: "https://example.com/a?trk_a=1&trk=2", want: "https://example.com/a?trk=2"},
+		{name: "trims spaces", raw: "  https://example.com/a  ", want: "https://example.com/a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.extra).Normalize(tt.raw)
+			if err != nil {
+				t.Fatalf("Normalize(%q) returned error: %v", tt.raw, err)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+	}{
+		{name: "relative", raw: "/feed"},
+		{name: "no host", raw: "mailto:someone@example.com"},
+		{name: "invalid", raw: "https://example.com/%zz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := New(nil).Normalize(tt.raw); err == nil {
+				t.Errorf("Normalize(%q) = %q, want an error", tt.raw, got)
+			}
+		})
+	}
+}
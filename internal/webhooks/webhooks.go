@@ -0,0 +1,142 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// EventNewPost is the event name sent for every newly saved post
+	EventNewPost = "post.created"
+	// maxAttempts is how many times a delivery is tried before giving up
+	maxAttempts = 3
+	// requestTimeout bounds a single delivery attempt
+	requestTimeout = 10 * time.Second
+)
+
+// retryBackoff is the wait before the second and later attempts
+var retryBackoff = []time.Duration{time.Second, 5 * time.Second}
+
+// Payload is the JSON body POSTed to a webhook endpoint
+type Payload struct {
+	Event  string      `json:"event"`
+	SentAt time.Time   `json:"sent_at"`
+	Feed   PayloadFeed `json:"feed"`
+	Post   PayloadPost `json:"post"`
+}
+
+type PayloadFeed struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type PayloadPost struct {
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	URL         string     `json:"url"`
+	Description string     `json:"description,omitempty"`
+	Author      string     `json:"author,omitempty"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+}
+
+// Result describes the outcome of a delivery after all retries
+type Result struct {
+	Attempts   int
+	StatusCode int
+	Err        error
+}
+
+// NewSecret generates a random secret used to sign deliveries
+func NewSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("cannot generate secret: %v", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// Sign returns the HMAC-SHA256 signature of body, formatted like "sha256=<hex>"
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Matches reports whether a post passes the webhook's keyword filter.
+// An empty keyword matches every post
+func Matches(keyword, title, description string) bool {
+	if keyword == "" {
+		return true
+	}
+	keyword = strings.ToLower(keyword)
+	return strings.Contains(strings.ToLower(title), keyword) ||
+		strings.Contains(strings.ToLower(description), keyword)
+}
+
+// Deliver POSTs the signed payload to url, retrying on network errors
+// and non-2xx responses
+func Deliver(ctx context.Context, url, secret string, payload Payload) Result {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return Result{Err: fmt.Errorf("cannot marshal payload: %v", err)}
+	}
+	signature := Sign(secret, body)
+
+	client := &http.Client{Timeout: requestTimeout}
+
+	var result Result
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				result.Err = ctx.Err()
+				return result
+			case <-time.After(retryBackoff[attempt-2]):
+			}
+		}
+
+		result.Attempts = attempt
+		result.StatusCode, result.Err = send(ctx, client, url, signature, payload.Event, body)
+		if result.Err == nil {
+			return result
+		}
+	}
+
+	return result
+}
+
+// send performs a single delivery attempt
+func send(ctx context.Context, client *http.Client, url, signature, event string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("request error: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "GoFlux")
+	req.Header.Set("X-GoFlux-Event", event)
+	req.Header.Set("X-GoFlux-Signature", signature)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("cannot get response: %v", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
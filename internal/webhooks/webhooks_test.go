@@ -0,0 +1,58 @@
+package webhooks
+
+import "testing"
+
+func TestSign(t *testing.T) {
+	tests := []struct {
+		name   string
+		secret string
+		body   string
+		want   string
+	}{
+		{
+			name:   "known vector",
+			secret: "key",
+			body:   "The quick brown fox jumps over the lazy dog",
+			want:   "sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8",
+		},
+		{
+			name:   "empty",
+			secret: "",
+			body:   "",
+			want:   "sha256=b613679a0814d9ec772f95d778c35fc5ff1697c493715653c6c712144292c5ad",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sign(tt.secret, []byte(tt.body)); got != tt.want {
+				t.Errorf("Sign(%q, %q) = %q, want %q", tt.secret, tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		name        string
+		keyword     string
+		title       string
+		description string
+		want        bool
+	}{
+		{name: "no keyword", keyword: "", title: "Anything", want: true},
+		{name: "in title", keyword: "go", title: "Go 1.23 released", want: true},
+		{name: "in description", keyword: "release", title: "News", description: "The RELEASE notes", want: true},
+		{name: "case insensitive keyword", keyword: "GO", title: "learning go", want: true},
+		{name: "substring", keyword: "go", title: "Algorithms", want: true},
+		{name: "missing", keyword: "rust", title: "Go 1.23 released", description: "Notes", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Matches(tt.keyword, tt.title, tt.description); got != tt.want {
+				t.Errorf("Matches(%q, %q, %q) = %v, want %v", tt.keyword, tt.title, tt.description, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package websub
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultLeaseSeconds is the lease GoFlux asks hubs for
+	DefaultLeaseSeconds = 7 * 24 * 60 * 60
+	// requestTimeout bounds a single request to a hub
+	requestTimeout = 30 * time.Second
+)
+
+// Subscribe asks the hub to start pushing updates of topicURL to callbackURL.
+// The hub confirms asynchronously by calling the callback with a challenge
+func Subscribe(ctx context.Context, hubURL, topicURL, callbackURL, secret string, leaseSeconds int) error {
+	return sendRequest(ctx, "subscribe", hubURL, topicURL, callbackURL, secret, leaseSeconds)
+}
+
+// Unsubscribe asks the hub to stop pushing updates of topicURL to callbackURL
+func Unsubscribe(ctx context.Context, hubURL, topicURL, callbackURL string) error {
+	return sendRequest(ctx, "unsubscribe", hubURL, topicURL, callbackURL, "", 0)
+}
+
+func sendRequest(ctx context.Context, mode, hubURL, topicURL, callbackURL, secret string, leaseSeconds int) error {
+	form := url.Values{}
+	form.Set("hub.mode", mode)
+	form.Set("hub.topic", topicURL)
+	form.Set("hub.callback", callbackURL)
+	if secret != "" {
+		form.Set("hub.secret", secret)
+	}
+	if leaseSeconds > 0 {
+		form.Set("hub.lease_seconds", strconv.Itoa(leaseSeconds))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hubURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("request error: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", "GoFlux")
+
+	client := &http.Client{Timeout: requestTimeout}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("cannot get response: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("hub rejected %s request: %d %s", mode, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return nil
+}
+
+// VerifySignature checks an X-Hub-Signature header ("<method>=<hex>")
+// against the HMAC of body computed with the subscription secret
+func VerifySignature(secret, header string, body []byte) bool {
+	method, signature, ok := strings.Cut(header, "=")
+	if !ok {
+		return false
+	}
+
+	var newHash func() hash.Hash
+	switch method {
+	case "sha1":
+		newHash = sha1.New
+	case "sha256":
+		newHash = sha256.New
+	case "sha384":
+		newHash = sha512.New384
+	case "sha512":
+		newHash = sha512.New
+	default:
+		return false
+	}
+
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
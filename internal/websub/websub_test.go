@@ -0,0 +1,50 @@
+package websub
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"hash"
+	"testing"
+)
+
+func sign(newHash func() hash.Hash, secret string, body []byte) string {
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifySignature(t *testing.T) {
+	secret := "s3cret"
+	body := []byte(`<feed xmlns="http://www.w3.org/2005/Atom"></feed>`)
+
+	tests := []struct {
+		name   string
+		secret string
+		header string
+		body   []byte
+		want   bool
+	}{
+		{name: "sha1", secret: secret, header: "sha1=" + sign(sha1.New, secret, body), body: body, want: true},
+		{name: "sha256", secret: secret, header: "sha256=" + sign(sha256.New, secret, body), body: body, want: true},
+		{name: "sha384", secret: secret, header: "sha384=" + sign(sha512.New384, secret, body), body: body, want: true},
+		{name: "sha512", secret: secret, header: "sha512=" + sign(sha512.New, secret, body), body: body, want: true},
+		{name: "wrong secret", secret: "other", header: "sha256=" + sign(sha256.New, secret, body), body: body},
+		{name: "changed body", secret: secret, header: "sha256=" + sign(sha256.New, secret, body), body: []byte("<feed/>")},
+		{name: "method mismatch", secret: secret, header: "sha512=" + sign(sha256.New, secret, body), body: body},
+		{name: "unknown method", secret: secret, header: "md5=" + sign(sha256.New, secret, body), body: body},
+		{name: "not hex", secret: secret, header: "sha256=zz", body: body},
+		{name: "no method", secret: secret, header: sign(sha256.New, secret, body), body: body},
+		{name: "missing header", secret: secret, header: "", body: body},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifySignature(tt.secret, tt.header, tt.body); got != tt.want {
+				t.Errorf("VerifySignature(%q, %q) = %v, want %v", tt.secret, tt.header, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+-- name: CreateAPIToken :one
+INSERT INTO api_tokens (id, created_at, user_id, name, token_hash, scopes, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+
+-- name: GetAPITokenByHash :one
+SELECT * FROM api_tokens
+WHERE token_hash = $1
+  AND (expires_at IS NULL OR expires_at > $2);
+
+
+-- name: GetAPITokensByUser :many
+SELECT * FROM api_tokens
+WHERE user_id = $1
+ORDER BY created_at;
+
+
+-- name: MarkAPITokenUsed :exec
+UPDATE api_tokens SET last_used_at = $2 WHERE id = $1;
+
+
+-- name: DeleteAPIToken :execrows
+DELETE FROM api_tokens WHERE user_id = $1 AND name = $2;
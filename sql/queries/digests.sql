@@ -0,0 +1,67 @@
+-- name: UpsertDigestSubscription :one
+INSERT INTO digest_subscriptions (user_id, created_at, updated_at, email, frequency)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (user_id) DO UPDATE
+SET email = EXCLUDED.email,
+    frequency = EXCLUDED.frequency,
+    updated_at = EXCLUDED.updated_at
+RETURNING *;
+
+
+-- name: GetDigestSubscriptionByUser :one
+SELECT * FROM digest_subscriptions WHERE user_id = $1;
+
+
+-- name: DeleteDigestSubscription :execrows
+DELETE FROM digest_subscriptions WHERE user_id = $1;
+
+
+-- name: ClaimNextDigest :one
+-- Leases a due digest subscription to one aggregator, so two never mail the
+-- same digest. Rows locked by another worker's claim are skipped
+UPDATE digest_subscriptions
+SET lease_owner = sqlc.arg(lease_owner),
+    lease_expires_at = sqlc.arg(lease_expires_at)
+WHERE user_id = (
+    SELECT user_id FROM digest_subscriptions
+    WHERE (last_sent_at IS NULL
+        OR last_sent_at <= sqlc.arg(now)::timestamp - CASE frequency
+            WHEN 'weekly' THEN INTERVAL '7 days'
+            ELSE INTERVAL '1 day'
+        END)
+        AND (lease_expires_at IS NULL OR lease_expires_at < sqlc.arg(now))
+    ORDER BY last_sent_at NULLS FIRST, user_id
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+
+-- name: ReleaseDigestLease :exec
+UPDATE digest_subscriptions
+SET lease_owner = NULL,
+    lease_expires_at = NULL
+WHERE user_id = $1 AND lease_owner = $2;
+
+
+-- name: MarkDigestSent :exec
+UPDATE digest_subscriptions
+SET last_sent_at = $2,
+    updated_at = $2
+WHERE user_id = $1;
+
+
+-- name: GetPostsForDigest :many
+SELECT 
+    p.title,
+    p.url,
+    p.description,
+    p.published_at,
+    f.id AS feed_id,
+    f.name AS feed_name
+FROM posts p
+JOIN feeds f ON p.feed_id = f.id
+JOIN feed_follows ff ON f.id = ff.feed_id
+WHERE ff.user_id = $1
+    AND p.created_at > $2
+ORDER BY f.name, f.id, p.published_at DESC;
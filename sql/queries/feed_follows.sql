@@ -38,5 +38,5 @@ DELETE FROM feed_follows
 WHERE
     feed_follows.user_id = $1
     AND feed_id IN (
-        SELECT id FROM feeds WHERE url = $2
+        SELECT id FROM feeds WHERE url = $2 OR normalized_url = $3
     );
\ No newline at end of file
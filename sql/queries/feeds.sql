@@ -1,12 +1,13 @@
 -- name: CreateFeed :one
-INSERT INTO feeds (id, created_at, updated_at, name, url, user_id)
+INSERT INTO feeds (id, created_at, updated_at, name, url, user_id, normalized_url)
 VALUES (
     $1,
     $2,
     $3,
     $4,
     $5,
-    $6
+    $6,
+    $7
 )
 RETURNING *;
 
@@ -24,7 +25,12 @@ JOIN
 
 
 -- name: GetFeedByUrl :one
-SELECT * FROM feeds WHERE url = $1;
+-- Matches the URL as given or normalized, so feeds added before URLs
+-- were normalized are still found
+SELECT * FROM feeds
+WHERE url = $1 OR normalized_url = $2
+ORDER BY normalized_url = $2 DESC
+LIMIT 1;
 
 
 
@@ -35,7 +41,57 @@ SET last_fetched_at = NOW(),
     updated_at = NOW()
 WHERE id = $1;
 
--- name: GetNextFeedToFetch :one
+-- name: ClaimNextFeed :one
+-- Leases the most overdue feed to one aggregator. Rows locked by another
+-- worker's claim are skipped, and expired leases of crashed workers are taken over
+UPDATE feeds
+SET lease_owner = sqlc.arg(lease_owner),
+    lease_expires_at = sqlc.arg(lease_expires_at)
+WHERE id = (
+    SELECT id FROM feeds
+    WHERE lease_expires_at IS NULL OR lease_expires_at < sqlc.arg(now)
+    ORDER BY last_fetched_at NULLS FIRST, updated_at ASC
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: ReleaseFeedLease :exec
+UPDATE feeds
+SET lease_owner = NULL,
+    lease_expires_at = NULL
+WHERE id = $1 AND lease_owner = $2;
+
+-- name: UpdateFeedMetadata :exec
+-- Refreshes what the feed says about itself on every fetch
+UPDATE feeds
+SET title = $2,
+    site_url = $3,
+    description = $4,
+    language = $5,
+    image_url = $6,
+    generator = $7
+WHERE id = $1;
+
+-- name: GetFeedByID :one
+SELECT * FROM feeds WHERE id = $1;
+
+
+-- name: GetFeeds :many
 SELECT * FROM feeds
-ORDER BY last_fetched_at NULLS FIRST, updated_at ASC
-LIMIT 1;
\ No newline at end of file
+ORDER BY name;
+
+
+-- name: DeleteFeed :exec
+DELETE FROM feeds WHERE id = $1;
+
+
+-- name: DeleteFeeds :exec
+DELETE FROM feeds;
+
+-- name: GetFeedUrls :many
+-- Every feed URL with its normalized form, oldest first
+SELECT id, url, normalized_url FROM feeds ORDER BY created_at;
+
+-- name: SetFeedNormalizedUrl :exec
+UPDATE feeds SET normalized_url = $2 WHERE id = $1;
@@ -1,6 +1,6 @@
 -- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, author, canonical_url, story_id, normalized_url)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 RETURNING *;
 
 -- name: GetPostsByUser :many
@@ -13,10 +13,95 @@ SELECT
     p.description,
     p.published_at,
     p.feed_id,
-    f.name AS feed_name
+    p.author,
+    p.story_id,
+    f.name AS feed_name,
+    (SELECT COUNT(*) FROM post_revisions r WHERE r.post_id = p.id) AS revision_count
 FROM posts p
 JOIN feeds f ON p.feed_id = f.id
 JOIN feed_follows ff ON f.id = ff.feed_id
-WHERE ff.user_id = $1
-ORDER BY p.published_at DESC
-LIMIT $2;
\ No newline at end of file
+WHERE ff.user_id = sqlc.arg(user_id)
+  AND (sqlc.narg(category)::text IS NULL OR EXISTS (
+    SELECT 1 FROM post_categories pc
+    JOIN categories c ON c.id = pc.category_id
+    WHERE pc.post_id = p.id AND lower(c.name) = lower(sqlc.narg(category))
+  ))
+  AND (sqlc.narg(author)::text IS NULL OR p.author ILIKE '%' || sqlc.narg(author) || '%')
+  AND NOT EXISTS (
+    -- Show each story once, as the first post seen in a followed feed
+    SELECT 1 FROM posts other
+    JOIN feed_follows other_follow ON other_follow.feed_id = other.feed_id AND other_follow.user_id = ff.user_id
+    WHERE other.story_id = p.story_id
+      AND (other.created_at < p.created_at OR (other.created_at = p.created_at AND other.id < p.id))
+  )
+ORDER BY CASE WHEN sqlc.arg(oldest_first)::boolean THEN p.published_at END ASC, p.published_at DESC
+LIMIT sqlc.arg('limit');
+
+-- name: SearchPostsByUser :many
+-- Matches the text in titles and descriptions, ignoring case
+SELECT 
+    p.id,
+    p.created_at,
+    p.updated_at,
+    p.title,
+    p.url,
+    p.description,
+    p.published_at,
+    p.feed_id,
+    p.author,
+    p.story_id,
+    f.name AS feed_name,
+    (SELECT COUNT(*) FROM post_revisions r WHERE r.post_id = p.id) AS revision_count
+FROM posts p
+JOIN feeds f ON p.feed_id = f.id
+JOIN feed_follows ff ON f.id = ff.feed_id
+WHERE ff.user_id = sqlc.arg(user_id)
+  AND (p.title ILIKE '%' || sqlc.arg(query)::text || '%' OR p.description ILIKE '%' || sqlc.arg(query)::text || '%')
+  AND (sqlc.narg(category)::text IS NULL OR EXISTS (
+    SELECT 1 FROM post_categories pc
+    JOIN categories c ON c.id = pc.category_id
+    WHERE pc.post_id = p.id AND lower(c.name) = lower(sqlc.narg(category))
+  ))
+  AND (sqlc.narg(author)::text IS NULL OR p.author ILIKE '%' || sqlc.narg(author) || '%')
+  AND NOT EXISTS (
+    -- Show each story once, as the first post seen in a followed feed
+    SELECT 1 FROM posts other
+    JOIN feed_follows other_follow ON other_follow.feed_id = other.feed_id AND other_follow.user_id = ff.user_id
+    WHERE other.story_id = p.story_id
+      AND (other.created_at < p.created_at OR (other.created_at = p.created_at AND other.id < p.id))
+  )
+ORDER BY CASE WHEN sqlc.arg(oldest_first)::boolean THEN p.published_at END ASC, p.published_at DESC
+LIMIT sqlc.arg('limit');
+
+-- name: GetPostByUrl :one
+-- Matches the URL as given or normalized, like GetFeedByUrl
+SELECT * FROM posts
+WHERE url = $1 OR normalized_url = $2
+ORDER BY normalized_url = $2 DESC
+LIMIT 1;
+
+-- name: GetPostByID :one
+SELECT * FROM posts WHERE id = $1;
+
+-- name: UpdatePostContent :exec
+UPDATE posts
+SET title = $2,
+    description = $3,
+    updated_at = $4
+WHERE id = $1;
+
+-- name: UpdatePostCanonical :exec
+UPDATE posts
+SET canonical_url = $2,
+    story_id = $3
+WHERE id = $1;
+
+-- name: DeletePosts :exec
+DELETE FROM posts;
+
+-- name: GetPostUrls :many
+-- Every post URL with its normalized form, oldest first
+SELECT id, url, normalized_url FROM posts ORDER BY created_at;
+
+-- name: SetPostNormalizedUrl :exec
+UPDATE posts SET normalized_url = $2 WHERE id = $1;
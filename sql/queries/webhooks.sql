@@ -0,0 +1,54 @@
+-- name: CreateWebhook :one
+INSERT INTO webhooks (id, created_at, updated_at, user_id, url, secret, feed_id, match_keyword)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+
+-- name: GetWebhooksByUser :many
+SELECT 
+    w.id,
+    w.created_at,
+    w.url,
+    w.match_keyword,
+    f.url AS feed_url
+FROM webhooks w
+LEFT JOIN feeds f ON w.feed_id = f.id
+WHERE w.user_id = $1
+ORDER BY w.created_at;
+
+
+-- name: GetWebhooksForFeed :many
+SELECT w.* FROM webhooks w
+WHERE EXISTS (
+        SELECT 1 FROM feed_follows ff
+        WHERE ff.user_id = w.user_id AND ff.feed_id = $1
+    )
+    AND (w.feed_id IS NULL OR w.feed_id = $1);
+
+
+-- name: DeleteWebhook :execrows
+DELETE FROM webhooks
+WHERE id = $1 AND user_id = $2;
+
+
+-- name: CreateWebhookDelivery :exec
+INSERT INTO webhook_deliveries (id, created_at, webhook_id, post_id, attempts, status_code, error, succeeded)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+
+-- name: GetWebhookDeliveriesByUser :many
+SELECT 
+    d.id,
+    d.created_at,
+    d.attempts,
+    d.status_code,
+    d.error,
+    d.succeeded,
+    w.url AS webhook_url,
+    p.title AS post_title
+FROM webhook_deliveries d
+JOIN webhooks w ON d.webhook_id = w.id
+JOIN posts p ON d.post_id = p.id
+WHERE w.user_id = $1
+ORDER BY d.created_at DESC
+LIMIT $2;
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE post_revisions (
+    id UUID PRIMARY KEY,
+    post_id UUID NOT NULL,
+    replaced_at TIMESTAMP NOT NULL,
+    title TEXT NOT NULL,
+    description TEXT,
+    FOREIGN KEY (post_id)
+        REFERENCES posts(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX post_revisions_post_id_idx ON post_revisions (post_id);
+
+-- +goose Down
+DROP TABLE post_revisions;
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE user_settings (
+    user_id UUID PRIMARY KEY,
+    updated_at TIMESTAMP NOT NULL,
+    timezone TEXT,
+    date_format TEXT,
+    browse_limit INTEGER,
+    sort_order TEXT,
+    language TEXT,
+    FOREIGN KEY (user_id)
+        REFERENCES users(id)
+        ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE user_settings;
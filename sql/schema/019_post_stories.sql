@@ -0,0 +1,27 @@
+-- +goose Up
+ALTER TABLE posts ADD COLUMN canonical_url TEXT;
+ALTER TABLE posts ADD COLUMN story_id UUID;
+UPDATE posts SET story_id = id;
+CREATE INDEX posts_story_id_idx ON posts (story_id);
+CREATE INDEX posts_canonical_url_idx ON posts (canonical_url);
+
+-- Feeds that carried a post under the exact URL of another feed's post
+CREATE TABLE post_feeds (
+    post_id UUID NOT NULL,
+    feed_id UUID NOT NULL,
+    seen_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (post_id, feed_id),
+    FOREIGN KEY (post_id)
+        REFERENCES posts(id)
+        ON DELETE CASCADE,
+    FOREIGN KEY (feed_id)
+        REFERENCES feeds(id)
+        ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE post_feeds;
+DROP INDEX posts_canonical_url_idx;
+DROP INDEX posts_story_id_idx;
+ALTER TABLE posts DROP COLUMN story_id;
+ALTER TABLE posts DROP COLUMN canonical_url;
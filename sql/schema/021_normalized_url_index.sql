@@ -0,0 +1,9 @@
+-- +goose Up
+-- Rows still without a normalized URL, for instance when migrating
+-- with the goose CLI, are left out until they get one
+CREATE UNIQUE INDEX feeds_normalized_url_idx ON feeds (normalized_url) WHERE normalized_url <> '';
+CREATE UNIQUE INDEX posts_normalized_url_idx ON posts (normalized_url) WHERE normalized_url <> '';
+
+-- +goose Down
+DROP INDEX posts_normalized_url_idx;
+DROP INDEX feeds_normalized_url_idx;
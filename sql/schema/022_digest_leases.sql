@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE digest_subscriptions ADD COLUMN lease_owner TEXT;
+ALTER TABLE digest_subscriptions ADD COLUMN lease_expires_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE digest_subscriptions DROP COLUMN lease_expires_at;
+ALTER TABLE digest_subscriptions DROP COLUMN lease_owner;
@@ -0,0 +1,9 @@
+// Package schema embeds the goose migrations so the binary can apply them itself
+package schema
+
+import "embed"
+
+// FS holds every migration file of this directory
+//
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,21 @@
+-- name: UpsertCategory :one
+INSERT INTO categories (id, name)
+VALUES (?, ?)
+ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+RETURNING *;
+
+-- name: AddPostCategory :exec
+INSERT INTO post_categories (post_id, category_id)
+VALUES (?, ?)
+ON CONFLICT DO NOTHING;
+
+-- name: GetPostCategories :many
+SELECT c.name
+FROM categories c
+JOIN post_categories pc ON pc.category_id = c.id
+WHERE pc.post_id = ?
+ORDER BY c.name;
+
+-- name: GetCategories :many
+SELECT * FROM categories
+ORDER BY name;
@@ -0,0 +1,44 @@
+-- name: CreateFeedFollow :one
+INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id)
+VALUES (?, ?, ?, ?, ?)
+RETURNING *;
+
+
+-- name: GetFeedFollowWithNames :one
+SELECT 
+    ff.id,
+    ff.created_at,
+    ff.updated_at,
+    ff.user_id,
+    ff.feed_id,
+    u.name AS user_name,
+    f.name AS feed_name
+FROM feed_follows ff
+JOIN users u ON ff.user_id = u.id
+JOIN feeds f ON ff.feed_id = f.id
+WHERE ff.id = ?;
+
+
+-- name: GetFeedFollowsByUser :many
+SELECT 
+    ff.id,
+    ff.created_at,
+    ff.updated_at,
+    ff.user_id,
+    ff.feed_id,
+    u.name AS user_name,
+    f.name AS feed_name,
+    f.url AS feed_url
+FROM feed_follows ff
+JOIN users u ON ff.user_id = u.id
+JOIN feeds f ON ff.feed_id = f.id
+WHERE ff.user_id = ?;
+
+
+-- name: DeleteFeedFollow :exec
+DELETE FROM feed_follows
+WHERE
+    feed_follows.user_id = ?
+    AND feed_id IN (
+        SELECT id FROM feeds WHERE url = ? OR normalized_url = ?
+    );
\ No newline at end of file
@@ -0,0 +1,17 @@
+-- name: UpsertFeedHTTPSettings :exec
+INSERT INTO feed_http_settings (feed_id, created_at, updated_at, encrypted_options)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (feed_id) DO UPDATE
+SET encrypted_options = EXCLUDED.encrypted_options,
+    updated_at = EXCLUDED.updated_at;
+
+
+-- name: GetFeedHTTPSettings :one
+SELECT * FROM feed_http_settings WHERE feed_id = ?;
+
+
+-- name: DeleteFeedHTTPSettings :exec
+DELETE FROM feed_http_settings WHERE feed_id = ?;
+
+-- name: CountFeedHTTPSettings :one
+SELECT COUNT(*) FROM feed_http_settings;
@@ -0,0 +1,96 @@
+-- name: CreateFeed :one
+INSERT INTO feeds (id, created_at, updated_at, name, url, user_id, normalized_url)
+VALUES (
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?
+)
+RETURNING *;
+
+
+-- name: GetFeedsWithUserNames :many
+SELECT 
+    f.name AS feed_name,
+    f.url AS feed_url,
+    u.name AS user_name
+FROM 
+    feeds f
+JOIN 
+    users u ON f.user_id = u.id;
+
+
+
+-- name: GetFeedByUrl :one
+-- Matches the URL as given or normalized, so feeds added before URLs
+-- were normalized are still found
+SELECT * FROM feeds
+WHERE url = ?1 OR normalized_url = ?2
+ORDER BY normalized_url = ?2 DESC
+LIMIT 1;
+
+
+
+
+-- name: MarkFeedFetched :exec
+UPDATE feeds 
+SET last_fetched_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'),
+    updated_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')
+WHERE id = ?;
+
+-- name: ClaimNextFeed :one
+-- Leases the most overdue feed to one aggregator. SQLite serializes writers,
+-- so no row locking is needed, and expired leases of crashed workers are taken over
+UPDATE feeds
+SET lease_owner = sqlc.arg(lease_owner),
+    lease_expires_at = sqlc.arg(lease_expires_at)
+WHERE id = (
+    SELECT id FROM feeds
+    WHERE lease_expires_at IS NULL OR lease_expires_at < sqlc.arg(now)
+    ORDER BY last_fetched_at NULLS FIRST, updated_at ASC
+    LIMIT 1
+)
+RETURNING *;
+
+-- name: ReleaseFeedLease :exec
+UPDATE feeds
+SET lease_owner = NULL,
+    lease_expires_at = NULL
+WHERE id = ? AND lease_owner = ?;
+
+-- name: UpdateFeedMetadata :exec
+-- Refreshes what the feed says about itself on every fetch
+UPDATE feeds
+SET title = ?2,
+    site_url = ?3,
+    description = ?4,
+    language = ?5,
+    image_url = ?6,
+    generator = ?7
+WHERE id = ?1;
+
+-- name: GetFeedByID :one
+SELECT * FROM feeds WHERE id = ?;
+
+
+-- name: GetFeeds :many
+SELECT * FROM feeds
+ORDER BY name;
+
+
+-- name: DeleteFeed :exec
+DELETE FROM feeds WHERE id = ?;
+
+
+-- name: DeleteFeeds :exec
+DELETE FROM feeds;
+
+-- name: GetFeedUrls :many
+-- Every feed URL with its normalized form, oldest first
+SELECT id, url, normalized_url FROM feeds ORDER BY created_at;
+
+-- name: SetFeedNormalizedUrl :exec
+UPDATE feeds SET normalized_url = ?2 WHERE id = ?1;
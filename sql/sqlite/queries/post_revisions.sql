@@ -0,0 +1,9 @@
+-- name: CreatePostRevision :exec
+-- Keeps a version of a post before the feed changed it
+INSERT INTO post_revisions (id, post_id, replaced_at, title, description)
+VALUES (?, ?, ?, ?, ?);
+
+-- name: GetPostRevisions :many
+SELECT * FROM post_revisions
+WHERE post_id = ?
+ORDER BY replaced_at;
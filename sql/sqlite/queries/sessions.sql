@@ -0,0 +1,17 @@
+-- name: CreateSession :exec
+INSERT INTO sessions (id, created_at, expires_at, token_hash, user_id)
+VALUES (?, ?, ?, ?, ?);
+
+
+-- name: GetSessionUser :one
+SELECT users.* FROM sessions
+JOIN users ON sessions.user_id = users.id
+WHERE sessions.token_hash = ? AND sessions.expires_at > ?;
+
+
+-- name: DeleteSession :exec
+DELETE FROM sessions WHERE token_hash = ?;
+
+
+-- name: DeleteUserSessions :exec
+DELETE FROM sessions WHERE user_id = ?;
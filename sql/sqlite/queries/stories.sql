@@ -0,0 +1,28 @@
+-- name: FindStoryByUrl :one
+-- Finds the story of another feed's post published under the same normalized URL
+SELECT story_id FROM posts
+WHERE (canonical_url = sqlc.arg(url) OR normalized_url = sqlc.arg(url))
+  AND feed_id <> sqlc.arg(feed_id)
+ORDER BY created_at
+LIMIT 1;
+
+-- name: GetRecentPostTitles :many
+-- Candidates for title matching: recent posts of other feeds
+SELECT id, story_id, title FROM posts
+WHERE feed_id <> ? AND created_at > ?;
+
+-- name: AddPostFeed :exec
+INSERT INTO post_feeds (post_id, feed_id, seen_at)
+VALUES (?, ?, ?)
+ON CONFLICT DO NOTHING;
+
+-- name: GetStoryFeeds :many
+SELECT f.id, f.name FROM feeds f
+WHERE f.id IN (
+    SELECT p.feed_id FROM posts p WHERE p.story_id = ?1
+    UNION
+    SELECT pf.feed_id FROM post_feeds pf
+    JOIN posts p ON p.id = pf.post_id
+    WHERE p.story_id = ?1
+)
+ORDER BY f.name;
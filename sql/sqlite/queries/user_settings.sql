@@ -0,0 +1,16 @@
+-- name: GetUserSettings :one
+SELECT * FROM user_settings WHERE user_id = ?;
+
+-- name: UpsertUserSettings :exec
+INSERT INTO user_settings (user_id, updated_at, timezone, date_format, browse_limit, sort_order, language)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (user_id) DO UPDATE
+SET updated_at = EXCLUDED.updated_at,
+    timezone = EXCLUDED.timezone,
+    date_format = EXCLUDED.date_format,
+    browse_limit = EXCLUDED.browse_limit,
+    sort_order = EXCLUDED.sort_order,
+    language = EXCLUDED.language;
+
+-- name: DeleteUserSettings :exec
+DELETE FROM user_settings WHERE user_id = ?;
@@ -0,0 +1,53 @@
+-- name: CreateUser :one
+INSERT INTO users (id, created_at, updated_at, name, password_hash, role)
+VALUES (
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?
+)
+RETURNING *;
+
+
+-- name: GetUser :one
+SELECT * FROM users WHERE name = ?;
+
+
+-- name: DeleteUsers :exec
+DELETE FROM users;
+
+
+-- name: GetUsers :many
+SELECT * FROM users;
+
+
+-- name: UpdateUserPassword :exec
+UPDATE users
+SET password_hash = ?2,
+    updated_at = ?3
+WHERE id = ?1;
+
+
+-- name: GetUserByID :one
+SELECT * FROM users WHERE id = ?;
+
+
+-- name: CountUsers :one
+SELECT COUNT(*) FROM users;
+
+
+-- name: CountUsersWithPassword :one
+SELECT COUNT(*) FROM users WHERE password_hash IS NOT NULL;
+
+
+-- name: SetUserRole :execrows
+UPDATE users
+SET role = ?2,
+    updated_at = ?3
+WHERE name = ?1;
+
+
+-- name: DeleteUser :execrows
+DELETE FROM users WHERE name = ?;
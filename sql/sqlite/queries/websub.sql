@@ -0,0 +1,38 @@
+-- name: UpsertWebSubSubscription :one
+INSERT INTO websub_subscriptions (feed_id, created_at, updated_at, hub_url, topic_url, secret)
+VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT (feed_id) DO UPDATE
+SET hub_url = EXCLUDED.hub_url,
+    topic_url = EXCLUDED.topic_url,
+    secret = EXCLUDED.secret,
+    updated_at = EXCLUDED.updated_at
+RETURNING *;
+
+
+-- name: GetWebSubSubscriptionByFeed :one
+SELECT * FROM websub_subscriptions WHERE feed_id = ?;
+
+
+-- name: GetWebSubSubscriptions :many
+SELECT 
+    w.feed_id,
+    w.hub_url,
+    w.topic_url,
+    w.verified_at,
+    w.lease_expires_at,
+    f.name AS feed_name
+FROM websub_subscriptions w
+JOIN feeds f ON w.feed_id = f.id
+ORDER BY f.name;
+
+
+-- name: MarkWebSubVerified :exec
+UPDATE websub_subscriptions
+SET verified_at = ?2,
+    lease_expires_at = ?3,
+    updated_at = ?2
+WHERE feed_id = ?1;
+
+
+-- name: DeleteWebSubSubscription :exec
+DELETE FROM websub_subscriptions WHERE feed_id = ?;
@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE feed_http_settings (
+    feed_id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    encrypted_options TEXT NOT NULL,
+    FOREIGN KEY (feed_id)
+        REFERENCES feeds(id)
+        ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE feed_http_settings;
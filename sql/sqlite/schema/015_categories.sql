@@ -0,0 +1,21 @@
+-- +goose Up
+CREATE TABLE categories (
+    id UUID PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE post_categories (
+    post_id UUID NOT NULL,
+    category_id UUID NOT NULL,
+    PRIMARY KEY (post_id, category_id),
+    FOREIGN KEY (post_id)
+        REFERENCES posts(id)
+        ON DELETE CASCADE,
+    FOREIGN KEY (category_id)
+        REFERENCES categories(id)
+        ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE post_categories;
+DROP TABLE categories;
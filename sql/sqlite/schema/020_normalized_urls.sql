@@ -0,0 +1,11 @@
+-- +goose Up
+-- Normalized URLs identify feeds and posts regardless of case, default
+-- ports, trailing slashes and tracking parameters. Existing rows are
+-- left empty here and normalized by 'goflux migrate up' before
+-- 021_normalized_url_index makes them unique
+ALTER TABLE feeds ADD COLUMN normalized_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE posts ADD COLUMN normalized_url TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE posts DROP COLUMN normalized_url;
+ALTER TABLE feeds DROP COLUMN normalized_url;